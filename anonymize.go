@@ -0,0 +1,455 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/hashicorp/terraform-json/sanitize"
+)
+
+// anonymizer replaces names in a plan with pseudonyms numbered in the order the names are found.
+// The same name always results in the same pseudonym within a run, so references still line up.
+// Pseudonyms don't derive from the names, so they can't be reversed without the mapping.
+type anonymizer struct {
+	// Mapping from pseudonym to original name
+	mapping map[string]string
+	// Pseudonyms already handed out, by kind and original name
+	pseudonyms map[string]map[string]string
+}
+
+func newAnonymizer() *anonymizer {
+	return &anonymizer{
+		mapping:    make(map[string]string),
+		pseudonyms: make(map[string]map[string]string),
+	}
+}
+
+// addressPart is a single segment of an address, like module.name[0] -> name, [0]
+type addressPart struct {
+	name  string
+	index string
+}
+
+// pseudonym returns the pseudonym of a name of the given kind (r(esource), m(odule), v(ariable), o(utput), l(ocal), k(ey), s(ource), f(ile), a(ttribute value))
+func (a *anonymizer) pseudonym(kind string, name string) string {
+	if name == "" {
+		return name
+	}
+
+	names, ok := a.pseudonyms[kind]
+	if !ok {
+		names = make(map[string]string)
+		a.pseudonyms[kind] = names
+	}
+	if p, ok := names[name]; ok {
+		return p
+	}
+
+	// Skip numbers whose pseudonym is already mapped to another name
+	n := len(names) + 1
+	p := fmt.Sprintf("%s_%d", kind, n)
+	for _, taken := a.mapping[p]; taken; _, taken = a.mapping[p] {
+		n++
+		p = fmt.Sprintf("%s_%d", kind, n)
+	}
+
+	names[name] = p
+	a.mapping[p] = name
+
+	return p
+}
+
+// value returns a copy of an attribute, output or variable value with its strings replaced by pseudonyms.
+// Equal strings get the same pseudonym, so changes are still visible. Numbers, bools and masked sensitive
+// values are kept. Values are copied since state plans share them between before, after and the state.
+func (a *anonymizer) value(v interface{}) interface{} {
+	switch x := v.(type) {
+	case string:
+		if x == sanitize.DefaultSensitiveValue {
+			return x
+		}
+		return a.pseudonym("a", x)
+	case map[string]interface{}:
+		if x == nil {
+			return x
+		}
+		anonymized := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			anonymized[k] = a.value(e)
+		}
+		return anonymized
+	case []interface{}:
+		if x == nil {
+			return x
+		}
+		anonymized := make([]interface{}, len(x))
+		for i, e := range x {
+			anonymized[i] = a.value(e)
+		}
+		return anonymized
+	}
+
+	return v
+}
+
+func (a *anonymizer) change(c *tfjson.Change) {
+	if c == nil {
+		return
+	}
+
+	c.Before = a.value(c.Before)
+	c.After = a.value(c.After)
+	if c.Importing != nil {
+		c.Importing.ID = a.pseudonym("a", c.Importing.ID)
+	}
+	// Generated configuration contains the original names and values
+	c.GeneratedConfig = ""
+}
+
+// index anonymizes string keys of for_each instances, numeric count indexes are kept
+func (a *anonymizer) index(index string) string {
+	if index == "" {
+		return index
+	}
+
+	key := strings.TrimSuffix(strings.TrimPrefix(index, "["), "]")
+	if key, err := strconv.Unquote(key); err == nil {
+		return fmt.Sprintf("[%q]", a.pseudonym("k", key))
+	}
+
+	return index
+}
+
+func (a *anonymizer) indexValue(index interface{}) interface{} {
+	if key, ok := index.(string); ok {
+		return a.pseudonym("k", key)
+	}
+	return index
+}
+
+// splitAddress splits an address on dots outside of instance keys
+func splitAddress(address string) []addressPart {
+	parts := []addressPart{}
+	depth := 0
+	quoted := false
+	start := 0
+	part := addressPart{}
+
+	for i := 0; i < len(address); i++ {
+		switch c := address[i]; {
+		case c == '"' && depth > 0 && (i == 0 || address[i-1] != '\\'):
+			quoted = !quoted
+		case quoted:
+		case c == '[':
+			if depth == 0 {
+				part.name = address[start:i]
+				start = i
+			}
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				part.index = address[start : i+1]
+				start = i + 1
+			}
+		case c == '.' && depth == 0:
+			if part.index == "" {
+				part.name = address[start:i]
+			}
+			parts = append(parts, part)
+			part = addressPart{}
+			start = i + 1
+		}
+	}
+
+	if part.index == "" {
+		part.name = address[start:]
+	}
+
+	return append(parts, part)
+}
+
+func joinAddress(parts []addressPart) string {
+	s := []string{}
+	for _, p := range parts {
+		s = append(s, fmt.Sprintf("%s%s", p.name, p.index))
+	}
+	return strings.Join(s, ".")
+}
+
+// address anonymizes a resource, module or reference address, keeping resource types and attributes
+func (a *anonymizer) address(address string) string {
+	if address == "" {
+		return address
+	}
+
+	parts := splitAddress(address)
+
+	for i := 0; i < len(parts); {
+		switch parts[i].name {
+		case "module":
+			if i+1 < len(parts) {
+				parts[i+1].name = a.pseudonym("m", parts[i+1].name)
+				parts[i+1].index = a.index(parts[i+1].index)
+			}
+			i += 2
+			// Module output reference
+			if i == len(parts)-1 && i > 1 {
+				parts[i].name = a.pseudonym("o", parts[i].name)
+				return joinAddress(parts)
+			}
+			continue
+		case "var":
+			if i+1 < len(parts) {
+				parts[i+1].name = a.pseudonym("v", parts[i+1].name)
+			}
+		case "local":
+			if i+1 < len(parts) {
+				parts[i+1].name = a.pseudonym("l", parts[i+1].name)
+			}
+		case "output":
+			if i+1 < len(parts) {
+				parts[i+1].name = a.pseudonym("o", parts[i+1].name)
+			}
+		case "each", "count", "path", "terraform", "self":
+		case "data":
+			if i+2 < len(parts) {
+				parts[i+2].name = a.pseudonym("r", parts[i+2].name)
+				parts[i+2].index = a.index(parts[i+2].index)
+			}
+		default:
+			if i+1 < len(parts) {
+				parts[i+1].name = a.pseudonym("r", parts[i+1].name)
+				parts[i+1].index = a.index(parts[i+1].index)
+			}
+		}
+		// Everything after the resource or value name is an attribute
+		break
+	}
+
+	return joinAddress(parts)
+}
+
+func (a *anonymizer) addresses(addresses []string) []string {
+	for i, address := range addresses {
+		addresses[i] = a.address(address)
+	}
+	return addresses
+}
+
+func (a *anonymizer) expression(e *tfjson.Expression) {
+	if e == nil || e.ExpressionData == nil {
+		return
+	}
+
+	a.addresses(e.References)
+	e.ConstantValue = a.value(e.ConstantValue)
+	for _, block := range e.NestedBlocks {
+		a.expressions(block)
+	}
+}
+
+func (a *anonymizer) expressions(expressions map[string]*tfjson.Expression) {
+	for _, e := range expressions {
+		a.expression(e)
+	}
+}
+
+func (a *anonymizer) resourceChange(rc *tfjson.ResourceChange) {
+	rc.Address = a.address(rc.Address)
+	rc.ModuleAddress = a.address(rc.ModuleAddress)
+	rc.Name = a.pseudonym("r", rc.Name)
+	rc.Index = a.indexValue(rc.Index)
+	a.change(rc.Change)
+}
+
+func (a *anonymizer) stateModule(module *tfjson.StateModule) {
+	if module == nil {
+		return
+	}
+
+	module.Address = a.address(module.Address)
+
+	for _, rst := range module.Resources {
+		rst.Address = a.address(rst.Address)
+		rst.Name = a.pseudonym("r", rst.Name)
+		rst.Index = a.indexValue(rst.Index)
+		a.addresses(rst.DependsOn)
+		if rst.AttributeValues != nil {
+			rst.AttributeValues = a.value(rst.AttributeValues).(map[string]interface{})
+		}
+	}
+
+	for _, childModule := range module.ChildModules {
+		a.stateModule(childModule)
+	}
+}
+
+func (a *anonymizer) stateValues(values *tfjson.StateValues) {
+	if values == nil {
+		return
+	}
+
+	outputs := make(map[string]*tfjson.StateOutput)
+	for name, o := range values.Outputs {
+		o.Value = a.value(o.Value)
+		outputs[a.pseudonym("o", name)] = o
+	}
+	values.Outputs = outputs
+
+	a.stateModule(values.RootModule)
+}
+
+func (a *anonymizer) configModule(module *tfjson.ConfigModule) {
+	if module == nil {
+		return
+	}
+
+	outputs := make(map[string]*tfjson.ConfigOutput)
+	for name, o := range module.Outputs {
+		a.expression(o.Expression)
+		a.addresses(o.DependsOn)
+		o.Description = ""
+		outputs[a.pseudonym("o", name)] = o
+	}
+	module.Outputs = outputs
+
+	variables := make(map[string]*tfjson.ConfigVariable)
+	for name, v := range module.Variables {
+		v.Default = a.value(v.Default)
+		v.Description = ""
+		variables[a.pseudonym("v", name)] = v
+	}
+	module.Variables = variables
+
+	for _, resource := range module.Resources {
+		resource.Address = a.address(resource.Address)
+		resource.Name = a.pseudonym("r", resource.Name)
+		a.expressions(resource.Expressions)
+		a.expression(resource.CountExpression)
+		a.expression(resource.ForEachExpression)
+		a.addresses(resource.DependsOn)
+		for _, p := range resource.Provisioners {
+			a.expressions(p.Expressions)
+		}
+	}
+
+	moduleCalls := make(map[string]*tfjson.ModuleCall)
+	for name, m := range module.ModuleCalls {
+		m.Source = a.pseudonym("s", m.Source)
+		a.expressions(m.Expressions)
+		a.expression(m.CountExpression)
+		a.expression(m.ForEachExpression)
+		a.addresses(m.DependsOn)
+		a.configModule(m.Module)
+		moduleCalls[a.pseudonym("m", name)] = m
+	}
+	module.ModuleCalls = moduleCalls
+}
+
+// diagnostics anonymizes the addresses and filenames of diagnostics. Details are free text that
+// quote names from the configuration, so they're dropped, summaries are generic and kept.
+func (a *anonymizer) diagnostics(diagnostics []Diagnostic) {
+	for i := range diagnostics {
+		diagnostics[i].Address = a.address(diagnostics[i].Address)
+		diagnostics[i].Filename = a.pseudonym("f", diagnostics[i].Filename)
+		diagnostics[i].Detail = ""
+	}
+}
+
+// AnonymizePlan replaces resource, module, variable, output and local names and string values
+// in the plan with pseudonyms, and drops descriptions. Resource types, attribute names and the
+// dependency structure are preserved. The mapping from pseudonyms to original names is written
+// to r.AnonymizeMapFile, within --outputDir unless it's an absolute path.
+func (r *rover) AnonymizePlan() error {
+	log.Println("Anonymizing plan...")
+
+	a := newAnonymizer()
+	p := r.Plan
+
+	for _, rc := range p.ResourceChanges {
+		a.resourceChange(rc)
+	}
+	for _, rc := range p.ResourceDrift {
+		a.resourceChange(rc)
+	}
+	for i := range p.RelevantAttributes {
+		p.RelevantAttributes[i].Resource = a.address(p.RelevantAttributes[i].Resource)
+	}
+
+	previousAddresses := make(map[string]string)
+	for address, previousAddress := range r.PreviousAddresses {
+		previousAddresses[a.address(address)] = a.address(previousAddress)
+	}
+	r.PreviousAddresses = previousAddresses
+
+	replaceReasons := make(map[string]string)
+	for address, reason := range r.ReplaceReasons {
+		replaceReasons[a.address(address)] = reason
+	}
+	r.ReplaceReasons = replaceReasons
+
+	a.diagnostics(r.Diagnostics)
+
+	outputChanges := make(map[string]*tfjson.Change)
+	for name, o := range p.OutputChanges {
+		a.change(o)
+		outputChanges[a.pseudonym("o", name)] = o
+	}
+	p.OutputChanges = outputChanges
+
+	variables := make(map[string]*tfjson.PlanVariable)
+	for name, v := range p.Variables {
+		v.Value = a.value(v.Value)
+		variables[a.pseudonym("v", name)] = v
+	}
+	p.Variables = variables
+
+	a.stateValues(p.PlannedValues)
+	if p.PriorState != nil {
+		a.stateValues(p.PriorState.Values)
+	}
+
+	if p.Config != nil {
+		providerConfigs := make(map[string]*tfjson.ProviderConfig)
+		for key, pc := range p.Config.ProviderConfigs {
+			// Keys of providers within modules are prefixed with the module address
+			if module, provider, ok := strings.Cut(key, ":"); ok {
+				key = fmt.Sprintf("%s:%s", a.address(module), provider)
+			}
+			pc.ModuleAddress = a.address(pc.ModuleAddress)
+			a.expressions(pc.Expressions)
+			providerConfigs[key] = pc
+		}
+		p.Config.ProviderConfigs = providerConfigs
+
+		a.configModule(p.Config.RootModule)
+	}
+
+	b, err := json.MarshalIndent(a.mapping, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error producing anonymization mapping JSON: %s", err)
+	}
+
+	mapFile := r.AnonymizeMapFile
+	if !filepath.IsAbs(mapFile) {
+		mapFile, err = r.outputPath(mapFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(mapFile, b, 0600); err != nil {
+		return fmt.Errorf("unable to write anonymization mapping (%s): %s", mapFile, err)
+	}
+
+	log.Printf("Saved anonymization mapping to %s", mapFile)
+
+	return nil
+}
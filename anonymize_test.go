@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestAnonymizeDiagnostics(t *testing.T) {
+	a := newAnonymizer()
+	diagnostics := []Diagnostic{
+		{
+			Severity: "warning",
+			Summary:  "Argument is deprecated",
+			Detail:   `The "secret_name" argument of null_resource.secret is deprecated.`,
+			Address:  "module.secret.null_resource.secret[\"key\"]",
+			Filename: "secret/main.tf",
+			Line:     3,
+		},
+		{
+			Severity: "error",
+			Summary:  "Invalid reference",
+		},
+	}
+
+	a.diagnostics(diagnostics)
+
+	want := []Diagnostic{
+		{
+			Severity: "warning",
+			Summary:  "Argument is deprecated",
+			Address:  a.address("module.secret.null_resource.secret[\"key\"]"),
+			Filename: a.pseudonym("f", "secret/main.tf"),
+			Line:     3,
+		},
+		{
+			Severity: "error",
+			Summary:  "Invalid reference",
+		},
+	}
+	if !reflect.DeepEqual(diagnostics, want) {
+		t.Errorf("diagnostics() = %+v, want %+v", diagnostics, want)
+	}
+	if a.mapping[diagnostics[0].Filename] != "secret/main.tf" {
+		t.Errorf("filename pseudonym %s isn't mapped to secret/main.tf", diagnostics[0].Filename)
+	}
+}
+
+func TestAnonymizerPseudonym(t *testing.T) {
+	a := newAnonymizer()
+
+	web := a.pseudonym("r", "web")
+	if web != "r_1" {
+		t.Errorf("pseudonym(r, web) = %s, want r_1", web)
+	}
+	if p := a.pseudonym("r", "web"); p != web {
+		t.Errorf("pseudonym(r, web) = %s on the second call, want %s", p, web)
+	}
+	if p := a.pseudonym("m", "web"); p != "m_1" {
+		t.Errorf("pseudonym(m, web) = %s, want m_1", p)
+	}
+
+	// A pseudonym already mapped to another name isn't handed out again
+	a.mapping["r_2"] = "taken"
+	if p := a.pseudonym("r", "db"); p != "r_3" {
+		t.Errorf("pseudonym(r, db) = %s, want r_3", p)
+	}
+	if a.mapping["r_2"] != "taken" {
+		t.Errorf("mapping of r_2 = %s, want taken", a.mapping["r_2"])
+	}
+}
+
+func TestAnonymizePlanValues(t *testing.T) {
+	// State plans share the attribute values between before, after and the state
+	values := map[string]interface{}{
+		"name":     "billing-db",
+		"port":     5432.0,
+		"password": "REDACTED_SENSITIVE",
+		"tags":     map[string]interface{}{"team": "billing"},
+	}
+
+	r := &rover{
+		OutputDir:        t.TempDir(),
+		AnonymizeMapFile: "map.json",
+		Plan: &tfjson.Plan{
+			ResourceChanges: []*tfjson.ResourceChange{{
+				Address: "aws_db_instance.billing",
+				Type:    "aws_db_instance",
+				Name:    "billing",
+				Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionNoop}, Before: values, After: values},
+			}},
+			PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+				Resources: []*tfjson.StateResource{{
+					Address:         "aws_db_instance.billing",
+					Type:            "aws_db_instance",
+					Name:            "billing",
+					AttributeValues: values,
+				}},
+			}},
+		},
+	}
+
+	if err := r.AnonymizePlan(); err != nil {
+		t.Fatalf("AnonymizePlan() error = %s", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(r.OutputDir, "map.json"))
+	if err != nil {
+		t.Fatalf("mapping wasn't written to --outputDir: %s", err)
+	}
+	mapping := map[string]string{}
+	if err := json.Unmarshal(b, &mapping); err != nil {
+		t.Fatal(err)
+	}
+
+	rc := r.Plan.ResourceChanges[0]
+	for _, got := range []interface{}{rc.Change.Before, rc.Change.After, r.Plan.PlannedValues.RootModule.Resources[0].AttributeValues} {
+		got := got.(map[string]interface{})
+		if name, _ := got["name"].(string); mapping[name] != "billing-db" {
+			t.Errorf("name = %v, want the pseudonym of billing-db", got["name"])
+		}
+		if team, _ := got["tags"].(map[string]interface{})["team"].(string); mapping[team] != "billing" {
+			t.Errorf("tags.team = %v, want the pseudonym of billing", got["tags"])
+		}
+		if got["port"] != 5432.0 || got["password"] != "REDACTED_SENSITIVE" {
+			t.Errorf("port = %v and password = %v, want them unchanged", got["port"], got["password"])
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const (
+	APPLY_RESULT_SUCCESS string = "success"
+	APPLY_RESULT_FAILED  string = "failed"
+	APPLY_RESULT_SKIPPED string = "skipped"
+)
+
+// applyLogMessage is the part of a machine readable apply log line describing a resource
+type applyLogMessage struct {
+	Type string `json:"type"`
+	Hook struct {
+		Resource struct {
+			Addr string `json:"addr"`
+		} `json:"resource"`
+	} `json:"hook"`
+}
+
+// getTFCApplyResults returns the apply outcome of every resource the plan of the run changes
+func getTFCApplyResults(client *tfe.Client, run *tfe.Run, plan *tfjson.Plan) (map[string]string, error) {
+	if run.Apply == nil || run.Apply.ID == "" {
+		return nil, fmt.Errorf("run %s has no apply", run.ID)
+	}
+
+	apply, err := client.Applies.Read(context.Background(), run.Apply.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read apply of run %s: %s", run.ID, err)
+	}
+
+	switch apply.Status {
+	case tfe.ApplyFinished, tfe.ApplyErrored, tfe.ApplyCanceled:
+	default:
+		return nil, fmt.Errorf("run %s hasn't been applied (apply is %s)", run.ID, apply.Status)
+	}
+
+	logs, err := client.Applies.Logs(context.Background(), apply.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read apply logs of run %s: %s", run.ID, err)
+	}
+
+	applied, err := parseApplyLog(logs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse apply logs of run %s: %s", run.ID, err)
+	}
+
+	return applyResults(plan, applied), nil
+}
+
+// parseApplyLog returns the outcome of the resources reported in a machine readable apply log
+func parseApplyLog(logs io.Reader) (map[string]string, error) {
+	applied := make(map[string]string)
+	machineReadable := false
+
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// Terraform Cloud wraps logs in STX and ETX control characters
+		line := strings.Trim(scanner.Text(), "\x02\x03")
+
+		var message applyLogMessage
+		if err := json.Unmarshal([]byte(line), &message); err != nil || message.Type == "" {
+			continue
+		}
+		machineReadable = true
+
+		switch message.Type {
+		case "apply_complete":
+			applied[message.Hook.Resource.Addr] = APPLY_RESULT_SUCCESS
+		case "apply_errored":
+			applied[message.Hook.Resource.Addr] = APPLY_RESULT_FAILED
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !machineReadable {
+		return nil, fmt.Errorf("logs aren't machine readable, which requires Terraform v0.15.3 or later")
+	}
+
+	return applied, nil
+}
+
+// applyResults returns the outcome of every resource change of the plan,
+// resources missing from the apply log were skipped
+func applyResults(plan *tfjson.Plan, applied map[string]string) map[string]string {
+	results := make(map[string]string)
+
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change == nil || rc.Change.Actions.NoOp() || rc.Change.Actions.Read() {
+			continue
+		}
+
+		result, ok := applied[rc.Address]
+		if !ok {
+			result = APPLY_RESULT_SKIPPED
+		}
+		results[rc.Address] = result
+	}
+
+	return results
+}
+
+// annotateApplyResults marks the nodes of applied resources with their outcome
+func (r *rover) annotateApplyResults(nodes []Node) {
+	for i, n := range nodes {
+		result, ok := r.ApplyResults[n.Data.ID]
+		if !ok {
+			continue
+		}
+
+		nodes[i].Data.ApplyResult = result
+		nodes[i].Classes = fmt.Sprintf("%s apply-%s", n.Classes, result)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// blastRadius returns the subgraph of the node with the given id and every node transitively
+// depending on it, up to depth hops away (no limit if depth is negative). Parents of the
+// included nodes are kept so the subgraph keeps its module and file grouping.
+func (r *rover) blastRadius(g Graph, id string, depth int) (Graph, bool) {
+	nodes := make(map[string]Node)
+	for _, n := range g.Nodes {
+		nodes[n.Data.ID] = n
+	}
+	if _, ok := nodes[id]; !ok {
+		return Graph{}, false
+	}
+
+	// Edges point from a node to its dependencies unless they were reversed
+	dependents := make(map[string][]string)
+	for _, e := range g.Edges {
+		if r.EdgeDirection == EDGE_DIRECTION_UPSTREAM {
+			dependents[e.Data.Source] = append(dependents[e.Data.Source], e.Data.Target)
+		} else {
+			dependents[e.Data.Target] = append(dependents[e.Data.Target], e.Data.Source)
+		}
+	}
+
+	affected := map[string]bool{id: true}
+	frontier := []string{id}
+	for hops := 0; len(frontier) > 0 && (depth < 0 || hops < depth); hops++ {
+		var nextFrontier []string
+		for _, current := range frontier {
+			for _, dependent := range dependents[current] {
+				if !affected[dependent] {
+					affected[dependent] = true
+					nextFrontier = append(nextFrontier, dependent)
+				}
+			}
+		}
+		frontier = nextFrontier
+	}
+
+	included := make(map[string]bool)
+	for affectedID := range affected {
+		for current := affectedID; current != "" && !included[current]; current = nodes[current].Data.Parent {
+			included[current] = true
+		}
+	}
+
+	subgraph := Graph{Nodes: []Node{}, Edges: []Edge{}, Layout: g.Layout}
+	for _, n := range g.Nodes {
+		if included[n.Data.ID] {
+			subgraph.Nodes = append(subgraph.Nodes, n)
+		}
+	}
+	for _, e := range g.Edges {
+		if affected[e.Data.Source] && affected[e.Data.Target] {
+			subgraph.Edges = append(subgraph.Edges, e)
+		}
+	}
+
+	return subgraph, true
+}
+
+// handleBlastRadius responds with the blast radius of the address query parameter,
+// limited to the number of hops in the optional depth query parameter
+func (ro *rover) handleBlastRadius(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		http.Error(w, "Please specify a resource address", http.StatusBadRequest)
+		return
+	}
+
+	depth := -1
+	if d := r.URL.Query().Get("depth"); d != "" {
+		var err error
+		depth, err = strconv.Atoi(d)
+		if err != nil || depth < 0 {
+			http.Error(w, fmt.Sprintf("Invalid depth %q: must be a non-negative integer", d), http.StatusBadRequest)
+			return
+		}
+	}
+
+	subgraph, ok := ro.blastRadius(ro.snapshot().Graph, address, depth)
+	if !ok {
+		http.Error(w, fmt.Sprintf("%s not found in graph", address), http.StatusNotFound)
+		return
+	}
+
+	j, err := json.Marshal(subgraph)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error producing graph JSON: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, string(j))
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// check is an input verified by --checkOnly, err is nil if it passed
+type check struct {
+	name string
+	err  error
+}
+
+// checkInputs verifies the inputs Rover needs to generate a plan, without generating it
+func (r *rover) checkInputs() []check {
+	checks := []check{}
+
+	// A provided plan JSON, state JSON or Terraform Cloud plan don't need the Terraform binary or configuration
+	usesTerraform := r.PlanJSONPath == "" && r.StateJSONPath == "" && !r.usesTFC()
+
+	if usesTerraform {
+		tfPath := r.TfPath
+		if tfPath == "" {
+			var err error
+			tfPath, err = r.Engine.FindPath()
+			if err != nil {
+				checks = append(checks, check{fmt.Sprintf("%s binary is found", r.Engine.Name()), err})
+			}
+		}
+		if tfPath != "" {
+			checks = append(checks, check{fmt.Sprintf("%s binary %s is executable", r.Engine.Name(), tfPath), checkExecutable(tfPath)})
+		}
+	}
+
+	switch {
+	case r.StateJSONPath != "":
+		checks = append(checks, check{fmt.Sprintf("state JSON %s exists", r.StateJSONPath), checkFile(r.StateJSONPath)})
+	case r.PlanPath != "":
+		checks = append(checks, check{fmt.Sprintf("plan file %s exists", r.PlanPath), checkFile(r.PlanPath)})
+	case isObjectURL(r.PlanJSONPath):
+		_, err := downloadObject(r.PlanJSONPath)
+		checks = append(checks, check{fmt.Sprintf("plan JSON %s is readable", r.PlanJSONPath), err})
+	case r.PlanJSONPath != "":
+		_, err := os.Stat(r.PlanJSONPath)
+		checks = append(checks, check{fmt.Sprintf("plan JSON %s exists", r.PlanJSONPath), err})
+	case r.usesTFC():
+		checks = append(checks, r.checkTFC()...)
+	default:
+		_, err := os.Stat(r.WorkingDir)
+		checks = append(checks, check{fmt.Sprintf("working directory %s exists", r.WorkingDir), err})
+
+		for _, tfVarsFile := range r.TfVarsFiles {
+			if tfVarsFile == "" {
+				continue
+			}
+			path := tfVarsFile
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(r.WorkingDir, path)
+			}
+			checks = append(checks, check{fmt.Sprintf("tfvars file %s exists", tfVarsFile), checkFile(path)})
+		}
+
+		_, err = r.backendConfigOptions()
+		checks = append(checks, check{"backend configs are valid", err})
+	}
+
+	return checks
+}
+
+// checkTFC verifies the Terraform Cloud credentials and that every workspace can be read
+func (r *rover) checkTFC() []check {
+	client, err := r.tfcClient()
+	checks := []check{{"Terraform Cloud is reachable with TFC_TOKEN", err}}
+	if err != nil {
+		return checks
+	}
+
+	if r.TFCWorkspaceTag != "" {
+		_, err := r.tfcWorkspaces(client)
+		return append(checks, check{fmt.Sprintf("workspaces tagged %s in %s organization are found", r.TFCWorkspaceTag, r.TFCOrgName), err})
+	}
+
+	for _, workspaceName := range r.TFCWorkspaceNames {
+		_, err := client.Workspaces.Read(context.Background(), r.TFCOrgName, workspaceName)
+		checks = append(checks, check{fmt.Sprintf("workspace %s in %s organization is readable", workspaceName, r.TFCOrgName), err})
+	}
+
+	return checks
+}
+
+// printChecks writes a checklist of the checks and returns true if all of them passed
+func printChecks(w io.Writer, checks []check) bool {
+	passed := true
+	for _, c := range checks {
+		if c.err != nil {
+			passed = false
+			fmt.Fprintf(w, "[FAIL] %s: %s\n", c.name, c.err)
+			continue
+		}
+		fmt.Fprintf(w, "[ OK ] %s\n", c.name)
+	}
+	return passed
+}
+
+func checkFile(path string) error {
+	_, err := statFile(path)
+	return err
+}
+
+func checkExecutable(path string) error {
+	info, err := statFile(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&0111 == 0 {
+		return fmt.Errorf("%s is not executable", path)
+	}
+	return nil
+}
+
+// statFile returns the file info of path, or an error if it doesn't exist or is a directory
+func statFile(path string) (os.FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	return info, nil
+}
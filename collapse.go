@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// instanceKeyPattern matches the count index or for_each key at the end of a resource instance address
+var instanceKeyPattern = regexp.MustCompile(`\[([0-9]+|"[^"]*")\]$`)
+
+// instanceKeysPattern matches the instance keys of an address and of the modules it is in
+var instanceKeysPattern = regexp.MustCompile(`\[([0-9]+|"[^"]*")\]`)
+
+// collapseInstances replaces the instance nodes of every resource with count or for_each by its
+// node, labeled with the number of instances. Edges of the instances are moved to the resource,
+// and edges ending up with the same source and target are merged, counting the references.
+func collapseInstances(nodes []Node, edges []Edge) ([]Node, []Edge) {
+	// Address of each instance's resource
+	resources := make(map[string]string)
+	instances := make(map[string][]Node)
+
+	for _, n := range nodes {
+		if n.Data.Type != ResourceTypeResource && n.Data.Type != ResourceTypeData {
+			continue
+		}
+
+		loc := instanceKeyPattern.FindStringIndex(n.Data.ID)
+		if loc == nil || n.Data.Parent != n.Data.ID[:loc[0]] {
+			continue
+		}
+
+		resources[n.Data.ID] = n.Data.Parent
+		instances[n.Data.Parent] = append(instances[n.Data.Parent], n)
+	}
+
+	if len(resources) == 0 {
+		return nodes, edges
+	}
+
+	collapsedNodes := make([]Node, 0, len(nodes)-len(resources))
+	for _, n := range nodes {
+		if _, ok := resources[n.Data.ID]; ok {
+			continue
+		}
+
+		if in, ok := instances[n.Data.ID]; ok {
+			n = collapsedNode(n, in)
+		}
+		collapsedNodes = append(collapsedNodes, n)
+	}
+
+	collapsedEdges := make([]Edge, 0, len(edges))
+	for _, e := range edges {
+		if resource, ok := resources[e.Data.Source]; ok {
+			e.Data.Source = resource
+		}
+		if resource, ok := resources[e.Data.Target]; ok {
+			e.Data.Target = resource
+		}
+		if e.Data.Source == e.Data.Target {
+			continue
+		}
+
+		e.Data.ID = fmt.Sprintf("%s->%s", e.Data.Source, e.Data.Target)
+		collapsedEdges = append(collapsedEdges, e)
+	}
+
+	return collapsedNodes, dedupeEdges(collapsedEdges)
+}
+
+// collapsedNode turns the node grouping the instances of a resource into a resource node. Its change
+// is the one shared by all instances, instances with different changes collapse into an update.
+func collapsedNode(n Node, instances []Node) Node {
+	change := instances[0].Data.Change
+	for _, in := range instances[1:] {
+		if in.Data.Change != change {
+			change = string(ActionUpdate)
+			break
+		}
+	}
+
+	n.Data.Label = fmt.Sprintf("%s (%d)", n.Data.Label, len(instances))
+	n.Data.Change = change
+	n.Data.Instances = len(instances)
+	n.Classes = fmt.Sprintf("%s-name %s", n.Data.Type, change)
+
+	return n
+}
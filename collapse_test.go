@@ -0,0 +1,29 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollapseInstancesEdges(t *testing.T) {
+	nodes := []Node{
+		{Data: NodeData{ID: "aws_instance.web", Type: ResourceTypeResource}},
+		{Data: NodeData{ID: "aws_instance.web[0]", Type: ResourceTypeResource, Parent: "aws_instance.web", Change: "create"}},
+		{Data: NodeData{ID: "aws_instance.web[1]", Type: ResourceTypeResource, Parent: "aws_instance.web", Change: "create"}},
+		{Data: NodeData{ID: "aws_security_group.web", Type: ResourceTypeResource}},
+	}
+	edges := []Edge{
+		edge("aws_instance.web[0]", "aws_security_group.web", "edge", 0),
+		edge("aws_instance.web[1]", "aws_security_group.web", "edge", 0),
+		// Already merged references keep their count
+		edge("aws_instance.web", "aws_security_group.web", "edge", 2),
+		edge("aws_instance.web[0]", "aws_instance.web[1]", "edge", 0),
+	}
+
+	_, got := collapseInstances(nodes, edges)
+
+	want := []Edge{edge("aws_instance.web", "aws_security_group.web", "edge", 4)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collapseInstances() edges = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+const (
+	COLOR_RESET  string = "\033[0m"
+	COLOR_CYAN   string = "\033[36m"
+	COLOR_GREEN  string = "\033[32m"
+	COLOR_YELLOW string = "\033[33m"
+)
+
+// colorLogs is true when status lines are colored with ANSI escape codes
+var colorLogs = false
+
+// setupColor enables colored status lines if stderr (where log writes) is a terminal
+func setupColor(noColor bool) {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		colorLogs = false
+		return
+	}
+
+	fi, err := os.Stderr.Stat()
+	if err != nil {
+		colorLogs = false
+		return
+	}
+
+	colorLogs = fi.Mode()&os.ModeCharDevice != 0
+}
+
+// logStatus logs a progress status line, colored if enabled
+func logStatus(color string, format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	if colorLogs {
+		msg = fmt.Sprintf("%s%s%s", color, msg, COLOR_RESET)
+	}
+	log.Println(msg)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// compactError summarizes the stderr captured from a failed Terraform command,
+// unless --verboseErrors is set, in which case the raw error is returned
+func (r *rover) compactError(err error, stderr *bytes.Buffer) string {
+	if r.VerboseErrors || stderr.Len() == 0 {
+		return err.Error()
+	}
+
+	return compactOutput(stderr.String())
+}
+
+// compactOutput strips the diagnostic box drawing from Terraform output and
+// deduplicates repeated lines, keeping the order they first appeared in
+func compactOutput(output string) string {
+	counts := make(map[string]int)
+	lines := []string{}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "╷│╵"))
+		if line == "" {
+			continue
+		}
+
+		if counts[line] == 0 {
+			lines = append(lines, line)
+		}
+		counts[line]++
+	}
+
+	for i, line := range lines {
+		if counts[line] > 1 {
+			lines[i] = fmt.Sprintf("%s (x%d)", line, counts[line])
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// estimateCost runs Infracost against the plan and returns its JSON cost breakdown. It returns
+// nil without running anything if the --infracostPath binary isn't found.
+func (r *rover) estimateCost() (json.RawMessage, error) {
+	infracostPath, err := exec.LookPath(r.InfracostPath)
+	if err != nil {
+		return nil, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "rover")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Infracost recognizes plan JSON files by their extension
+	planJSONPath := filepath.Join(tmpDir, "plan.json")
+	planJson, err := json.Marshal(r.Plan)
+	if err != nil {
+		return nil, fmt.Errorf("error producing plan JSON for Infracost: %s", err)
+	}
+	if err := os.WriteFile(planJSONPath, planJson, 0600); err != nil {
+		return nil, fmt.Errorf("unable to write plan JSON for Infracost: %s", err)
+	}
+
+	log.Println("Estimating costs with Infracost...")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(infracostPath, "breakdown", "--path", planJSONPath, "--format", "json", "--no-color")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("infracost failed: %s: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	if !json.Valid(stdout.Bytes()) {
+		return nil, fmt.Errorf("infracost returned invalid JSON")
+	}
+
+	return json.RawMessage(stdout.Bytes()), nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// markCriticalPath marks the nodes and edges on the longest dependency chain in the graph.
+// If the graph contains a cycle, it is reported and nothing is marked.
+func markCriticalPath(g *Graph) {
+	adjacency := make(map[string][]int)
+	for i, e := range g.Edges {
+		adjacency[e.Data.Source] = append(adjacency[e.Data.Source], i)
+	}
+
+	sources := make([]string, 0, len(adjacency))
+	for source := range adjacency {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int)
+	length := make(map[string]int)
+	next := make(map[string]int)
+	var stack []string
+	var cycle []string
+
+	var visit func(id string) bool
+	visit = func(id string) bool {
+		switch state[id] {
+		case visited:
+			return true
+		case visiting:
+			// Unwind the stack to the start of the cycle
+			for i, s := range stack {
+				if s == id {
+					cycle = append(append(cycle, stack[i:]...), id)
+					break
+				}
+			}
+			return false
+		}
+
+		state[id] = visiting
+		stack = append(stack, id)
+
+		for _, i := range adjacency[id] {
+			target := g.Edges[i].Data.Target
+			if !visit(target) {
+				return false
+			}
+			if length[target]+1 > length[id] {
+				length[id] = length[target] + 1
+				next[id] = i
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[id] = visited
+		return true
+	}
+
+	start := ""
+	for _, source := range sources {
+		if !visit(source) {
+			log.Printf("Unable to compute critical path, dependency cycle detected: %s", strings.Join(cycle, " -> "))
+			return
+		}
+		if start == "" || length[source] > length[start] {
+			start = source
+		}
+	}
+
+	if start == "" {
+		return
+	}
+
+	criticalNodes := map[string]bool{start: true}
+	for id := start; length[id] > 0; id = g.Edges[next[id]].Data.Target {
+		e := &g.Edges[next[id]]
+		e.Data.Critical = true
+		e.Classes = fmt.Sprintf("%s critical", e.Classes)
+		criticalNodes[e.Data.Target] = true
+	}
+
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if criticalNodes[n.Data.ID] {
+			n.Data.Critical = true
+			n.Classes = fmt.Sprintf("%s critical", n.Classes)
+		}
+	}
+
+	log.Printf("Critical path is %d dependencies long, starting at %s", length[start], start)
+}
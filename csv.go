@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+const (
+	CSV_NODES_FILE string = "nodes.csv"
+	CSV_EDGES_FILE string = "edges.csv"
+)
+
+// writeGraphCSV saves the nodes and edges of the graph as CSV files to nodesFile and edgesFile
+func (r *rover) writeGraphCSV(nodesFile string, edgesFile string) error {
+	if err := writeCSVFile(nodesFile, r.writeNodesCSV); err != nil {
+		return err
+	}
+	if err := writeCSVFile(edgesFile, r.writeEdgesCSV); err != nil {
+		return err
+	}
+
+	log.Printf("Saved graph to %s and %s", nodesFile, edgesFile)
+
+	return nil
+}
+
+func writeCSVFile(filename string, write func(io.Writer) error) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("unable to write CSV (%s): %s", filename, err)
+	}
+	defer f.Close()
+
+	if err := write(f); err != nil {
+		return fmt.Errorf("unable to write CSV (%s): %s", filename, err)
+	}
+
+	return f.Close()
+}
+
+// writeNodesCSV writes a row with the address, type and action of every graph node
+func (r *rover) writeNodesCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"address", "type", "action"})
+	for _, n := range r.Graph.Nodes {
+		action, _ := nodeAction(n)
+		cw.Write([]string{n.Data.ID, string(n.Data.Type), string(action)})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeEdgesCSV writes a row for every graph edge. The kind of an edge is the type of the node
+// it references, e.g. variable for a reference to a variable.
+func (r *rover) writeEdgesCSV(w io.Writer) error {
+	types := map[string]ResourceType{}
+	for _, n := range r.Graph.Nodes {
+		types[n.Data.ID] = n.Data.Type
+	}
+
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"source", "target", "kind"})
+	for _, e := range r.Graph.Edges {
+		referenced := e.Data.Target
+		if r.EdgeDirection == EDGE_DIRECTION_UPSTREAM {
+			referenced = e.Data.Source
+		}
+		cw.Write([]string{e.Data.Source, e.Data.Target, string(types[referenced])})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+const (
+	FORMAT_ZIP       string = "zip"
+	FORMAT_CYTOSCAPE string = "cytoscape"
+	FORMAT_CSV       string = "csv"
+)
+
+// CytoscapeGraph is the graph in the elements JSON format cytoscape.js loads
+type CytoscapeGraph struct {
+	Elements CytoscapeElements `json:"elements"`
+}
+
+// CytoscapeElements are the nodes and edges of a CytoscapeGraph
+type CytoscapeElements struct {
+	Nodes []CytoscapeElement `json:"nodes"`
+	Edges []CytoscapeElement `json:"edges"`
+}
+
+// CytoscapeElement is a node or edge. Its data fields can be used in cytoscape.js style selectors, e.g. node[action = "delete"].
+type CytoscapeElement struct {
+	Data    map[string]interface{} `json:"data"`
+	Classes string                 `json:"classes,omitempty"`
+}
+
+// nodeAction returns the action of a resource node and whether the resource is imported,
+// since the change of imported resources is their action followed by "import"
+func nodeAction(n Node) (Action, bool) {
+	action, importing := strings.CutSuffix(n.Data.Change, " import")
+	return Action(action), importing
+}
+
+// cytoscapeGraph converts the graph to the cytoscape.js elements format
+func (r *rover) cytoscapeGraph() CytoscapeGraph {
+	g := CytoscapeGraph{
+		Elements: CytoscapeElements{
+			Nodes: []CytoscapeElement{},
+			Edges: []CytoscapeElement{},
+		},
+	}
+
+	for _, n := range r.Graph.Nodes {
+		data := map[string]interface{}{
+			"id":    n.Data.ID,
+			"label": n.Data.Label,
+			"type":  string(n.Data.Type),
+		}
+		if n.Data.Parent != "" {
+			data["parent"] = n.Data.Parent
+		}
+
+		if n.Data.Change != "" {
+			action, importing := nodeAction(n)
+			data["action"] = action
+			data["importing"] = importing
+		}
+		if n.Data.MovedFrom != "" {
+			data["movedFrom"] = n.Data.MovedFrom
+		}
+		if n.Data.Critical {
+			data["critical"] = true
+		}
+		if n.Data.ApplyResult != "" {
+			data["applyResult"] = n.Data.ApplyResult
+		}
+		if n.Data.PolicyResult != "" {
+			data["policyResult"] = n.Data.PolicyResult
+		}
+		if n.Data.Drift {
+			data["drift"] = true
+		}
+		if n.Data.ReplaceReason != "" {
+			data["replaceReason"] = n.Data.ReplaceReason
+		}
+		if n.Data.Instances > 0 {
+			data["instances"] = n.Data.Instances
+		}
+		if n.Data.Group != "" {
+			data["group"] = n.Data.Group
+		}
+		if n.Data.Unknown {
+			data["unknown"] = true
+		}
+
+		g.Elements.Nodes = append(g.Elements.Nodes, CytoscapeElement{Data: data, Classes: n.Classes})
+	}
+
+	for _, e := range r.Graph.Edges {
+		data := map[string]interface{}{
+			"id":     e.Data.ID,
+			"source": e.Data.Source,
+			"target": e.Data.Target,
+		}
+		if e.Data.Critical {
+			data["critical"] = true
+		}
+		if e.Data.Count > 0 {
+			data["count"] = e.Data.Count
+		}
+
+		g.Elements.Edges = append(g.Elements.Edges, CytoscapeElement{Data: data, Classes: e.Classes})
+	}
+
+	return g
+}
+
+// writeCytoscapeGraph saves the graph as cytoscape.js JSON to filename
+func (r *rover) writeCytoscapeGraph(filename string) error {
+	b, err := json.MarshalIndent(r.cytoscapeGraph(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error producing cytoscape JSON: %s", err)
+	}
+
+	if err := os.WriteFile(filename, b, 0644); err != nil {
+		return fmt.Errorf("unable to write cytoscape graph (%s): %s", filename, err)
+	}
+
+	log.Printf("Saved cytoscape graph to %s", filename)
+
+	return nil
+}
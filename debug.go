@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+)
+
+// saveJSONToFile saves j as indented JSON to <fileType>.json in the --outputDir
+func (r *rover) saveJSONToFile(fileType string, j interface{}) (string, error) {
+	path, err := r.outputPath(fmt.Sprintf("%s.json", fileType))
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error producing %s JSON: %s", fileType, err)
+	}
+
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		return "", fmt.Errorf("unable to write %s JSON (%s): %s", fileType, path, err)
+	}
+
+	return path, nil
+}
+
+// saveDebugJSON saves the plan and the assets generated from it for troubleshooting, with --debug.
+// The plan is sanitized unless --showSensitive is set.
+func (r *rover) saveDebugJSON() error {
+	assets := []struct {
+		fileType string
+		j        interface{}
+	}{
+		{"plan", r.Plan},
+		{"rso", r.RSO},
+		{"map", r.Map},
+		{"graph", r.Graph},
+	}
+
+	for _, asset := range assets {
+		path, err := r.saveJSONToFile(asset.fileType, asset.j)
+		if err != nil {
+			return err
+		}
+		log.Printf("Saved %s to %s", asset.fileType, path)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// truncateModuleDepth leaves the modules nested deeper than --maxModuleDepth, and their resources, out
+// of the plan, and records whether any were left out. The RSO, Map and Graph are built by recursing
+// into every module, so a pathological plan is cut short before it can exhaust the stack. The plan
+// is walked without recursion.
+func (r *rover) truncateModuleDepth() {
+	r.ModulesTruncated = false
+	if r.MaxModuleDepth <= 0 || r.Plan == nil {
+		return
+	}
+
+	truncated := false
+	if r.Plan.PlannedValues != nil {
+		truncated = truncateStateModule(r.Plan.PlannedValues.RootModule, r.MaxModuleDepth) || truncated
+	}
+	if r.Plan.PriorState != nil && r.Plan.PriorState.Values != nil {
+		truncated = truncateStateModule(r.Plan.PriorState.Values.RootModule, r.MaxModuleDepth) || truncated
+	}
+	if r.Plan.Config != nil {
+		truncated = truncateConfigModule(r.Plan.Config.RootModule, r.MaxModuleDepth) || truncated
+	}
+
+	// Partial plans may only nest modules in the addresses of their resource changes
+	var dropped bool
+	r.Plan.ResourceChanges, dropped = truncateResourceChanges(r.Plan.ResourceChanges, r.MaxModuleDepth)
+	truncated = dropped || truncated
+	r.Plan.ResourceDrift, dropped = truncateResourceChanges(r.Plan.ResourceDrift, r.MaxModuleDepth)
+	truncated = dropped || truncated
+
+	if truncated {
+		logStatus(COLOR_YELLOW, "WARNING: plan nests modules more than the --maxModuleDepth of %d levels deep, leaving out the deeper modules", r.MaxModuleDepth)
+	}
+	r.ModulesTruncated = truncated
+}
+
+// moduleDepth returns how many modules deep a resource or module address is nested
+func moduleDepth(address string) int {
+	parts := splitAddress(address)
+
+	depth := 0
+	for i := 0; i+1 < len(parts) && parts[i].name == "module"; i += 2 {
+		depth++
+	}
+
+	return depth
+}
+
+// truncateResourceChanges drops the resource changes of modules nested deeper than maxDepth
+func truncateResourceChanges(changes []*tfjson.ResourceChange, maxDepth int) ([]*tfjson.ResourceChange, bool) {
+	kept := changes[:0]
+	for _, rc := range changes {
+		if moduleDepth(rc.Address) <= maxDepth {
+			kept = append(kept, rc)
+		}
+	}
+
+	return kept, len(kept) < len(changes)
+}
+
+// truncateStateModule drops the child modules nested deeper than maxDepth below module
+func truncateStateModule(module *tfjson.StateModule, maxDepth int) bool {
+	type level struct {
+		module *tfjson.StateModule
+		depth  int
+	}
+
+	truncated := false
+	stack := []level{{module, 0}}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current.module == nil {
+			continue
+		}
+
+		if current.depth >= maxDepth {
+			truncated = truncated || len(current.module.ChildModules) > 0
+			current.module.ChildModules = nil
+			continue
+		}
+		for _, child := range current.module.ChildModules {
+			stack = append(stack, level{child, current.depth + 1})
+		}
+	}
+
+	return truncated
+}
+
+// truncateConfigModule drops the module calls nested deeper than maxDepth below module
+func truncateConfigModule(module *tfjson.ConfigModule, maxDepth int) bool {
+	type level struct {
+		module *tfjson.ConfigModule
+		depth  int
+	}
+
+	truncated := false
+	stack := []level{{module, 0}}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current.module == nil {
+			continue
+		}
+
+		if current.depth >= maxDepth {
+			truncated = truncated || len(current.module.ModuleCalls) > 0
+			current.module.ModuleCalls = nil
+			continue
+		}
+		for _, call := range current.module.ModuleCalls {
+			if call != nil {
+				stack = append(stack, level{call.Module, current.depth + 1})
+			}
+		}
+	}
+
+	return truncated
+}
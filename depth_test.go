@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestModuleDepth(t *testing.T) {
+	tests := []struct {
+		address string
+		want    int
+	}{
+		{address: "aws_instance.web", want: 0},
+		{address: "module.app", want: 1},
+		{address: "module.app.aws_instance.web", want: 1},
+		{address: `module.app["a.b"].module.db[0].aws_db_instance.main`, want: 2},
+		{address: "aws_instance.module", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if got := moduleDepth(tt.address); got != tt.want {
+				t.Errorf("moduleDepth(%q) = %d, want %d", tt.address, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateModuleDepth(t *testing.T) {
+	r := &rover{
+		MaxModuleDepth: 1,
+		Plan: &tfjson.Plan{
+			PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+				ChildModules: []*tfjson.StateModule{{
+					Address:      "module.app",
+					ChildModules: []*tfjson.StateModule{{Address: "module.app.module.db"}},
+				}},
+			}},
+			Config: &tfjson.Config{RootModule: &tfjson.ConfigModule{
+				ModuleCalls: map[string]*tfjson.ModuleCall{
+					"app": {Module: &tfjson.ConfigModule{
+						ModuleCalls: map[string]*tfjson.ModuleCall{"db": {Module: &tfjson.ConfigModule{}}},
+					}},
+				},
+			}},
+			ResourceChanges: []*tfjson.ResourceChange{
+				{Address: "aws_instance.web"},
+				{Address: "module.app.aws_instance.api"},
+				{Address: "module.app.module.db.aws_db_instance.main"},
+			},
+		},
+	}
+
+	r.truncateModuleDepth()
+
+	if !r.ModulesTruncated {
+		t.Error("ModulesTruncated isn't set")
+	}
+	if app := r.Plan.PlannedValues.RootModule.ChildModules[0]; len(app.ChildModules) != 0 {
+		t.Errorf("planned values still contain %d modules in module.app", len(app.ChildModules))
+	}
+	if app := r.Plan.Config.RootModule.ModuleCalls["app"].Module; len(app.ModuleCalls) != 0 {
+		t.Errorf("configuration still calls %d modules in module.app", len(app.ModuleCalls))
+	}
+	if len(r.Plan.ResourceChanges) != 2 || r.Plan.ResourceChanges[1].Address != "module.app.aws_instance.api" {
+		t.Errorf("resource changes = %v, want aws_instance.web and module.app.aws_instance.api", r.Plan.ResourceChanges)
+	}
+}
+
+func TestTruncateModuleDepthResourceChangesOnly(t *testing.T) {
+	// Bare partial plans only nest modules in the addresses of their resource changes
+	r := &rover{
+		MaxModuleDepth: 1,
+		Plan: &tfjson.Plan{ResourceChanges: []*tfjson.ResourceChange{
+			{Address: "module.a.module.b.aws_instance.web"},
+		}},
+	}
+
+	r.truncateModuleDepth()
+
+	if !r.ModulesTruncated || len(r.Plan.ResourceChanges) != 0 {
+		t.Errorf("ModulesTruncated = %v with %d resource changes left, want true with none", r.ModulesTruncated, len(r.Plan.ResourceChanges))
+	}
+
+	r.MaxModuleDepth = 2
+	r.Plan.ResourceChanges = []*tfjson.ResourceChange{{Address: "module.a.module.b.aws_instance.web"}}
+	r.truncateModuleDepth()
+
+	if r.ModulesTruncated || len(r.Plan.ResourceChanges) != 1 {
+		t.Errorf("ModulesTruncated = %v with %d resource changes left, want false with one", r.ModulesTruncated, len(r.Plan.ResourceChanges))
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// PLAN_JSON_TF_VERSION is the first version supporting terraform plan -json
+const PLAN_JSON_TF_VERSION = "0.15.3"
+
+// Diagnostic is a warning or error emitted by Terraform while planning
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// uiMessage is a line of Terraform's machine readable UI output (-json)
+type uiMessage struct {
+	Type       string `json:"type"`
+	Diagnostic *struct {
+		Severity string `json:"severity"`
+		Summary  string `json:"summary"`
+		Detail   string `json:"detail"`
+		Address  string `json:"address"`
+		Range    *struct {
+			Filename string `json:"filename"`
+			Start    struct {
+				Line int `json:"line"`
+			} `json:"start"`
+		} `json:"range"`
+	} `json:"diagnostic"`
+}
+
+// supportsPlanJSON returns true if the detected Terraform version can stream plan diagnostics as JSON
+func (r *rover) supportsPlanJSON() bool {
+	tfVersion, err := version.NewVersion(r.TfVersion)
+	if err != nil {
+		return false
+	}
+	return !tfVersion.LessThan(version.Must(version.NewVersion(PLAN_JSON_TF_VERSION)))
+}
+
+// parseDiagnostics extracts the diagnostics from Terraform's machine readable UI output
+func parseDiagnostics(output []byte) []Diagnostic {
+	diagnostics := []Diagnostic{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var msg uiMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil || msg.Type != "diagnostic" || msg.Diagnostic == nil {
+			continue
+		}
+
+		d := Diagnostic{
+			Severity: msg.Diagnostic.Severity,
+			Summary:  msg.Diagnostic.Summary,
+			Detail:   msg.Diagnostic.Detail,
+			Address:  msg.Diagnostic.Address,
+		}
+		if msg.Diagnostic.Range != nil {
+			d.Filename = msg.Diagnostic.Range.Filename
+			d.Line = msg.Diagnostic.Range.Start.Line
+		}
+
+		diagnostics = append(diagnostics, d)
+	}
+
+	return diagnostics
+}
+
+// diagnosticsError summarizes all error diagnostics, or returns an empty string if there are none
+func diagnosticsError(diagnostics []Diagnostic) string {
+	errs := []string{}
+	for _, d := range diagnostics {
+		if d.Severity != "error" {
+			continue
+		}
+
+		e := d.Summary
+		if d.Filename != "" {
+			e = fmt.Sprintf("%s (%s line %d)", e, d.Filename, d.Line)
+		}
+		if d.Detail != "" {
+			e = fmt.Sprintf("%s: %s", e, d.Detail)
+		}
+		errs = append(errs, e)
+	}
+
+	return compactOutput(strings.Join(errs, "\n"))
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// isDrift returns true if a change is a no-op whose prior and planned values differ, which is how
+// refresh-only plans report changes made outside of Terraform
+func isDrift(change *tfjson.Change) bool {
+	return change != nil && change.Actions.NoOp() && !reflect.DeepEqual(change.Before, change.After)
+}
+
+// annotateDrift marks the nodes of drifted resources.
+// Collapsed resources are marked if any of their instances drifted.
+func (r *rover) annotateDrift(nodes []Node) {
+	if r.Plan == nil {
+		return
+	}
+
+	// Addresses of the drifted resource instances, and of the resources collapsed instances belong to
+	drifted := map[string]bool{}
+	driftedResources := map[string]bool{}
+	for _, rc := range r.Plan.ResourceChanges {
+		if !isDrift(rc.Change) {
+			continue
+		}
+
+		drifted[rc.Address] = true
+		if loc := instanceKeyPattern.FindStringIndex(rc.Address); loc != nil {
+			driftedResources[rc.Address[:loc[0]]] = true
+		}
+	}
+
+	for i, n := range nodes {
+		if !drifted[n.Data.ID] && !(n.Data.Instances > 0 && driftedResources[n.Data.ID]) {
+			continue
+		}
+
+		nodes[i].Data.Drift = true
+		nodes[i].Classes = fmt.Sprintf("%s drift", n.Classes)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAnnotateDrift(t *testing.T) {
+	tests := []struct {
+		name              string
+		collapseInstances bool
+		want              map[string]bool
+	}{
+		{
+			name: "instances",
+			want: map[string]bool{
+				"null_resource.a":    true,
+				"null_resource.b[0]": true,
+				"null_resource.b[1]": false,
+				"null_resource.c":    false,
+			},
+		},
+		{
+			name:              "collapsed instances",
+			collapseInstances: true,
+			want: map[string]bool{
+				"null_resource.a": true,
+				"null_resource.b": true,
+				"null_resource.c": false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testRover(t, "refresh-only.json")
+			r.CollapseInstances = tt.collapseInstances
+			if err := r.generateAssets(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]bool{}
+			for _, n := range r.Graph.Nodes {
+				if _, ok := tt.want[n.Data.ID]; !ok {
+					continue
+				}
+				got[n.Data.ID] = n.Data.Drift
+
+				hasClass := false
+				for _, class := range strings.Fields(n.Classes) {
+					hasClass = hasClass || class == "drift"
+				}
+				if hasClass != n.Data.Drift {
+					t.Errorf("node %s has drift %v but classes %q", n.Data.ID, n.Data.Drift, n.Classes)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("drift of graph nodes = %v, want %v", got, tt.want)
+			}
+
+			drifts := append([]string{}, r.RSO.Drifts...)
+			sort.Strings(drifts)
+			if want := []string{"null_resource.a", "null_resource.b[0]"}; !reflect.DeepEqual(drifts, want) {
+				t.Errorf("RSO drifts = %v, want %v", drifts, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Engine is the binary Rover uses to generate plans
+type Engine string
+
+const (
+	// EngineTerraform denotes HashiCorp Terraform.
+	EngineTerraform Engine = "terraform"
+
+	// EngineOpenTofu denotes OpenTofu, a drop-in fork of Terraform.
+	EngineOpenTofu Engine = "tofu"
+)
+
+// Name returns the human readable name of the engine
+func (e Engine) Name() string {
+	if e == EngineOpenTofu {
+		return "OpenTofu"
+	}
+	return "Terraform"
+}
+
+// DefaultPath returns the binary location used when --tfPath is not set
+func (e Engine) DefaultPath() string {
+	return fmt.Sprintf("/bin/%s", e)
+}
+
+// FindPath returns the binary used when --tfPath is not set: the default location if it exists,
+// or else the binary found in PATH
+func (e Engine) FindPath() (string, error) {
+	if _, err := os.Stat(e.DefaultPath()); err == nil {
+		return e.DefaultPath(), nil
+	}
+
+	path, err := exec.LookPath(string(e))
+	if err != nil {
+		return "", fmt.Errorf("%s binary not found in %s or PATH, set it with --tfPath", e.Name(), e.DefaultPath())
+	}
+
+	return path, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+func TestEngineDefaultPath(t *testing.T) {
+	tests := []struct {
+		engine Engine
+		want   string
+	}{
+		{EngineTerraform, "/bin/terraform"},
+		{EngineOpenTofu, "/bin/tofu"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.engine), func(t *testing.T) {
+			if got := tt.engine.DefaultPath(); got != tt.want {
+				t.Errorf("DefaultPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngineName(t *testing.T) {
+	if got := EngineTerraform.Name(); got != "Terraform" {
+		t.Errorf("Name() = %q, want Terraform", got)
+	}
+	if got := EngineOpenTofu.Name(); got != "OpenTofu" {
+		t.Errorf("Name() = %q, want OpenTofu", got)
+	}
+}
+
+func TestEngineFindPath(t *testing.T) {
+	if _, err := os.Stat(EngineOpenTofu.DefaultPath()); err == nil {
+		t.Skipf("%s exists, which FindPath prefers over PATH", EngineOpenTofu.DefaultPath())
+	}
+
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+
+	if _, err := EngineOpenTofu.FindPath(); err == nil {
+		t.Error("FindPath() found tofu in an empty PATH")
+	}
+
+	tofu := filepath.Join(dir, "tofu")
+	if err := os.WriteFile(tofu, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := EngineOpenTofu.FindPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != tofu {
+		t.Errorf("FindPath() = %q, want %q", got, tofu)
+	}
+}
+
+// TestOpenTofuWithTfexec runs tofu through tfexec like Rover runs Terraform
+func TestOpenTofuWithTfexec(t *testing.T) {
+	if _, err := exec.LookPath(string(EngineOpenTofu)); err != nil {
+		t.Skip("tofu is not on PATH")
+	}
+
+	path, err := EngineOpenTofu.FindPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tf, err := tfexec.NewTerraform(t.TempDir(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version, _, err := tf.Version(context.Background(), true)
+	if err != nil {
+		t.Fatalf("unable to get the version of %s through tfexec: %s", path, err)
+	}
+	t.Logf("%s is OpenTofu %s", path, version)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+)
+
+// Kinds of errors generating the plan, so callers can tell them apart with errors.Is
+var (
+	ErrNoTFCToken    = errors.New("TFC_TOKEN environment variable not set")
+	ErrNoTFCOrg      = errors.New("must specify Terraform Cloud organization to retrieve plan from Terraform Cloud")
+	ErrInitFailed    = errors.New("init failed")
+	ErrPlanFailed    = errors.New("plan failed")
+	ErrPlanParse     = errors.New("plan parse failed")
+	ErrTFCRunTimeout = errors.New("timeout waiting for Terraform Cloud run")
+)
+
+// kindError is an error of a kind like ErrPlanParse. It keeps the message of the wrapped error,
+// which already describes the failure, and unwraps to both the kind and the wrapped error.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// withKind marks err as an error of the given kind
+func withKind(kind error, err error) error {
+	return &kindError{kind: kind, err: err}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// EVENT_MAX_BYTES is the maximum size of an event posted to /api/event
+	EVENT_MAX_BYTES int64 = 4096
+	// EVENT_MAX_TYPE_LENGTH is the maximum length of the type of an event
+	EVENT_MAX_TYPE_LENGTH int = 64
+)
+
+// eventLogMu serializes appending to --eventLog, which configurations served together share
+var eventLogMu sync.Mutex
+
+// Event is an interaction of a reviewer with the frontend, such as clicking a node
+type Event struct {
+	Type    string `json:"type"`
+	Address string `json:"address,omitempty"`
+	// Timestamp is set by Rover when the event is received, not by the frontend
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// appendEvent appends the event to the --eventLog file as a JSON line
+func (r *rover) appendEvent(e Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	eventLogMu.Lock()
+	defer eventLogMu.Unlock()
+
+	f, err := os.OpenFile(r.EventLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to open event log (%s): %s", r.EventLog, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("unable to write event log (%s): %s", r.EventLog, err)
+	}
+
+	return nil
+}
+
+// handleEvent appends an interaction event posted by the frontend to the --eventLog file
+func (ro *rover) handleEvent(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Events must be posted with POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var e Event
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, EVENT_MAX_BYTES)).Decode(&e); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("Event exceeds %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Invalid event JSON: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if e.Type == "" || len(e.Type) > EVENT_MAX_TYPE_LENGTH {
+		http.Error(w, fmt.Sprintf("Invalid event type %q: must be 1 to %d characters", e.Type, EVENT_MAX_TYPE_LENGTH), http.StatusBadRequest)
+		return
+	}
+	e.Timestamp = time.Now().UTC()
+
+	if err := ro.appendEvent(e); err != nil {
+		http.Error(w, fmt.Sprintf("Error recording event: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// keepResourceChange returns false if the resource change should be left out of the visualization
+func (r *rover) keepResourceChange(rc *tfjson.ResourceChange) bool {
+	if rc.Change == nil {
+		return true
+	}
+
+	if r.ChangesOnly && rc.Change.Actions.NoOp() {
+		return false
+	}
+
+	if len(r.OnlyActions) > 0 && !r.OnlyActions[changeAction(rc.Change.Actions)] {
+		return false
+	}
+
+	if len(r.OnlyResources) > 0 && !matchesAddress(r.OnlyResources, rc.Address) {
+		return false
+	}
+
+	if matchesAddress(r.ExcludeResources, rc.Address) {
+		return false
+	}
+
+	return true
+}
+
+// parseAddressPatterns parses resource addresses in which * matches any characters
+func parseAddressPatterns(patterns []string) []*regexp.Regexp {
+	parsed := []*regexp.Regexp{}
+	for _, pattern := range patterns {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+
+		expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+		parsed = append(parsed, regexp.MustCompile(fmt.Sprintf("^%s$", expr)))
+	}
+
+	return parsed
+}
+
+// matchesAddress returns true if a pattern matches the address. Patterns without instance keys
+// match every instance of the resource.
+func matchesAddress(patterns []*regexp.Regexp, address string) bool {
+	resource := instanceKeysPattern.ReplaceAllString(address, "")
+	for _, pattern := range patterns {
+		if pattern.MatchString(address) || pattern.MatchString(resource) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// changeAction returns the Action shown for the actions of a resource change
+func changeAction(actions tfjson.Actions) Action {
+	if len(actions) == 0 {
+		return ActionNoop
+	}
+	if len(actions) > 1 {
+		return ActionReplace
+	}
+	return Action(string(actions[0]))
+}
+
+// parseActions parses a comma-separated list of actions such as delete,replace
+func parseActions(s string) (map[Action]bool, error) {
+	actions := make(map[Action]bool)
+	if s == "" {
+		return actions, nil
+	}
+
+	for _, name := range strings.Split(s, ",") {
+		action := Action(strings.TrimSpace(name))
+		switch action {
+		case ActionNoop, ActionCreate, ActionRead, ActionUpdate, ActionDelete, ActionReplace:
+			actions[action] = true
+		default:
+			return nil, fmt.Errorf("unknown action %q, must be one of no-op, create, read, update, delete, replace", name)
+		}
+	}
+
+	return actions, nil
+}
+
+// FilterPlan removes resources excluded by the filter flags from the plan,
+// before the RSO, Map and Graph are generated from it
+func (r *rover) FilterPlan() {
+	r.FilteredResources = nil
+
+	if !r.ChangesOnly && len(r.OnlyActions) == 0 && len(r.OnlyResources) == 0 && len(r.ExcludeResources) == 0 {
+		return
+	}
+
+	removed := make(map[string]bool)
+	resourceChanges := []*tfjson.ResourceChange{}
+
+	for _, rc := range r.Plan.ResourceChanges {
+		if r.keepResourceChange(rc) {
+			resourceChanges = append(resourceChanges, rc)
+		} else {
+			removed[rc.Address] = true
+		}
+	}
+
+	if len(removed) == 0 {
+		return
+	}
+
+	log.Printf("Filtered %d resources out of the plan", len(removed))
+
+	r.Plan.ResourceChanges = resourceChanges
+
+	// Resources are only gone from the graph once all of their instances are
+	r.FilteredResources = make(map[string]bool)
+	for address := range removed {
+		r.FilteredResources[instanceKeysPattern.ReplaceAllString(address, "")] = true
+	}
+	for _, rc := range resourceChanges {
+		delete(r.FilteredResources, instanceKeysPattern.ReplaceAllString(rc.Address, ""))
+	}
+
+	if r.Plan.PlannedValues != nil && r.Plan.PlannedValues.RootModule != nil {
+		filterStateModule(r.Plan.PlannedValues.RootModule, removed)
+	}
+
+	if r.Plan.PriorState != nil && r.Plan.PriorState.Values != nil && r.Plan.PriorState.Values.RootModule != nil {
+		filterStateModule(r.Plan.PriorState.Values.RootModule, removed)
+	}
+}
+
+// dropFilteredEdges removes the edges from and to resources filtered out of the plan,
+// since their nodes aren't in the graph
+func (r *rover) dropFilteredEdges(edges []Edge) []Edge {
+	kept := make([]Edge, 0, len(edges))
+	for _, e := range edges {
+		if r.FilteredResources[e.Data.Source] || r.FilteredResources[e.Data.Target] {
+			continue
+		}
+		kept = append(kept, e)
+	}
+
+	return kept
+}
+
+// filterStateModule removes resources from the module and its children,
+// dropping child modules left without any resources
+func filterStateModule(module *tfjson.StateModule, removed map[string]bool) {
+	resources := []*tfjson.StateResource{}
+	for _, rst := range module.Resources {
+		if !removed[rst.Address] {
+			resources = append(resources, rst)
+		}
+	}
+	module.Resources = resources
+
+	childModules := []*tfjson.StateModule{}
+	for _, childModule := range module.ChildModules {
+		filterStateModule(childModule, removed)
+		if len(childModule.Resources) > 0 || len(childModule.ChildModules) > 0 {
+			childModules = append(childModules, childModule)
+		}
+	}
+	module.ChildModules = childModules
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// inModuleFocus returns true if address is the focused module or lives inside of it.
+// Without a focus, every address is in focus.
+func (r *rover) inModuleFocus(address string) bool {
+	if r.ModuleFocus == "" {
+		return true
+	}
+
+	return address == r.ModuleFocus ||
+		strings.HasPrefix(address, fmt.Sprintf("%s.", r.ModuleFocus)) ||
+		strings.HasPrefix(address, fmt.Sprintf("%s[", r.ModuleFocus))
+}
+
+// isModuleFocusAncestor returns true if address is a module containing the focused module
+func (r *rover) isModuleFocusAncestor(address string) bool {
+	if r.ModuleFocus == "" || address == r.ModuleFocus {
+		return false
+	}
+
+	return address == "" ||
+		strings.HasPrefix(r.ModuleFocus, fmt.Sprintf("%s.", address)) ||
+		strings.HasPrefix(r.ModuleFocus, fmt.Sprintf("%s[", address))
+}
+
+// FocusResourceOverview removes every state and config outside of the focused module.
+// Ancestor modules are kept so the focused module can still be reached from the root,
+// but their children are trimmed down to the path leading to the focused module.
+func (r *rover) FocusResourceOverview(rso *ResourcesOverview) error {
+	if r.ModuleFocus == "" {
+		return nil
+	}
+
+	if _, ok := rso.States[r.ModuleFocus]; !ok {
+		return fmt.Errorf("module %s not found in plan", r.ModuleFocus)
+	}
+
+	for id, state := range rso.States {
+		if r.inModuleFocus(id) {
+			continue
+		}
+
+		if !r.isModuleFocusAncestor(id) {
+			delete(rso.States, id)
+			continue
+		}
+
+		for childID := range state.Children {
+			if !r.inModuleFocus(childID) && !r.isModuleFocusAncestor(childID) {
+				delete(state.Children, childID)
+			}
+		}
+	}
+
+	for id := range rso.Configs {
+		if !r.inModuleFocus(id) && !r.isModuleFocusAncestor(id) {
+			delete(rso.Configs, id)
+		}
+	}
+
+	return nil
+}
+
+// addBoundaryNodes adds a stub node for every edge endpoint outside of the focused module,
+// so dependencies crossing the module boundary are still visible.
+func (r *rover) addBoundaryNodes(nodes []Node, edges []Edge) []Node {
+	if r.ModuleFocus == "" {
+		return nodes
+	}
+
+	exists := make(map[string]bool)
+	for _, n := range nodes {
+		exists[n.Data.ID] = true
+	}
+
+	basePath := strings.ReplaceAll(r.Map.Path, "./", "")
+
+	for _, e := range edges {
+		for _, id := range []string{e.Data.Source, e.Data.Target} {
+			if exists[id] {
+				continue
+			}
+
+			nodes = append(nodes, Node{
+				Data: NodeData{
+					ID:     id,
+					Label:  id,
+					Type:   ResourceTypeBoundary,
+					Parent: basePath,
+				},
+				Classes: "boundary",
+			})
+			exists[id] = true
+		}
+	}
+
+	return nodes
+}
+
+// isPinned returns true if address is one of the --pinModules modules, lives inside of one or
+// contains one, so that the path to a pinned module stays expanded
+func (r *rover) isPinned(address string) bool {
+	for _, pinned := range r.PinModules {
+		if address == pinned ||
+			strings.HasPrefix(address, fmt.Sprintf("%s.", pinned)) ||
+			strings.HasPrefix(address, fmt.Sprintf("%s[", pinned)) ||
+			strings.HasPrefix(pinned, fmt.Sprintf("%s.", address)) ||
+			strings.HasPrefix(pinned, fmt.Sprintf("%s[", address)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,8 @@
+//go:build !noui
+
+package main
+
+import "embed"
+
+//go:embed ui/dist
+var frontend embed.FS
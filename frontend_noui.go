@@ -0,0 +1,9 @@
+//go:build noui
+
+package main
+
+import "embed"
+
+// frontend is empty in binaries built with -tags noui, for building Rover without building the
+// frontend first. Only modes not serving or rendering the frontend work in them.
+var frontend embed.FS
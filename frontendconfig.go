@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// DEFAULT_FRONTEND_CONFIG is served at /config.json unless a config is fetched with --configURL
+const DEFAULT_FRONTEND_CONFIG = `{}`
+
+// FRONTEND_CONFIG_TIMEOUT bounds fetching the frontend config at startup
+const FRONTEND_CONFIG_TIMEOUT = 10 * time.Second
+
+// fetchFrontendConfig fetches the frontend config JSON from url,
+// falling back to the default config if it can't be fetched
+func fetchFrontendConfig(url string) json.RawMessage {
+	config, err := getFrontendConfig(url)
+	if err != nil {
+		logStatus(COLOR_YELLOW, "WARNING: unable to fetch frontend config from %s, using default config: %s", url, err)
+		return json.RawMessage(DEFAULT_FRONTEND_CONFIG)
+	}
+
+	log.Printf("Fetched frontend config from %s", url)
+	return config
+}
+
+func getFrontendConfig(url string) (json.RawMessage, error) {
+	client := &http.Client{Timeout: FRONTEND_CONFIG_TIMEOUT}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("invalid config JSON: %s", err)
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// frontendConfig returns the frontend config fetched at startup, with the preferences set by flags.
+// --staticLayout sets staticLayout, so the frontend renders the graph without animations, and
+// --eventLog sets eventLog, so the frontend posts interaction events to /api/event.
+func (ro *rover) frontendConfig() (json.RawMessage, error) {
+	config := ro.FrontendConfig
+	if config == nil {
+		config = json.RawMessage(DEFAULT_FRONTEND_CONFIG)
+	}
+
+	if !ro.StaticLayout && ro.EventLog == "" {
+		return config, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(config, &fields); err != nil {
+		return nil, fmt.Errorf("invalid config JSON: %s", err)
+	}
+	if fields == nil {
+		fields = map[string]json.RawMessage{}
+	}
+	if ro.StaticLayout {
+		fields["staticLayout"] = json.RawMessage("true")
+	}
+	if ro.EventLog != "" {
+		fields["eventLog"] = json.RawMessage("true")
+	}
+
+	return json.Marshal(fields)
+}
+
+// handleFrontendConfig serves the frontend config
+func (ro *rover) handleFrontendConfig(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+
+	config, err := ro.frontendConfig()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error producing frontend config: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(config)
+}
@@ -20,10 +20,22 @@ const (
 	LOCAL_COLOR     string = "black"
 )
 
+const (
+	LAYOUT_TOP_BOTTOM string = "TB"
+	LAYOUT_LEFT_RIGHT string = "LR"
+)
+
+const (
+	EDGE_DIRECTION_DOWNSTREAM string = "downstream"
+	EDGE_DIRECTION_UPSTREAM   string = "upstream"
+)
+
 // ModuleGraph TODO
 type Graph struct {
 	Nodes []Node `json:"nodes"`
 	Edges []Edge `json:"edges"`
+	// Layout is the rank direction the graph should be laid out in
+	Layout string `json:"layout,omitempty"`
 }
 
 // Node TODO
@@ -40,6 +52,23 @@ type NodeData struct {
 	Parent      string       `json:"parent,omitempty"`
 	ParentColor string       `json:"parentColor,omitempty"`
 	Change      string       `json:"change,omitempty"`
+	Critical    bool         `json:"critical,omitempty"`
+	// ApplyResult is the outcome of applying the resource, with --showApplyResult
+	ApplyResult string `json:"applyResult,omitempty"`
+	// PolicyResult is the status of the policy check the resource fails, with --showPolicies
+	PolicyResult string `json:"policyResult,omitempty"`
+	// Instances is the number of instances collapsed into the node, with --collapseInstances
+	Instances int `json:"instances,omitempty"`
+	// MovedFrom is the previous address of a resource moved without other changes
+	MovedFrom string `json:"movedFrom,omitempty"`
+	// Group is the value of the resource's tag, with --groupByTag
+	Group string `json:"group,omitempty"`
+	// Drift is set if the resource changed outside of Terraform
+	Drift bool `json:"drift,omitempty"`
+	// ReplaceReason is why a replaced resource is replaced
+	ReplaceReason string `json:"replaceReason,omitempty"`
+	// Unknown is set if some of the resource's values are only known after apply, with --highlightUnknown
+	Unknown bool `json:"unknown,omitempty"`
 }
 
 // Edge TODO
@@ -54,6 +83,9 @@ type EdgeData struct {
 	Source   string `json:"source"`
 	Target   string `json:"target"`
 	Gradient string `json:"gradient,omitempty"`
+	Critical bool   `json:"critical,omitempty"`
+	// Count is the number of references collapsed into the edge, if more than one
+	Count int `json:"count,omitempty"`
 }
 
 // GenerateGraph -
@@ -63,6 +95,22 @@ func (r *rover) GenerateGraph() error {
 	nodes := r.GenerateNodes()
 	edges := r.GenerateEdges()
 
+	if len(r.FilteredResources) > 0 {
+		edges = r.dropFilteredEdges(edges)
+	}
+
+	if r.showOutputs() {
+		nodes, edges = r.addOutputNodes(nodes, edges)
+	}
+
+	if r.CollapseInstances {
+		nodes, edges = collapseInstances(nodes, edges)
+	}
+
+	if r.EdgeDirection == EDGE_DIRECTION_UPSTREAM {
+		edges = reverseEdges(edges)
+	}
+
 	// Edge case for terraform.workspace
 	for _, e := range edges {
 		if strings.Contains(e.Data.ID, "terraform.workspace") {
@@ -80,9 +128,44 @@ func (r *rover) GenerateGraph() error {
 		}
 	}
 
+	// Stub out dependencies crossing the focused module boundary
+	nodes = r.addBoundaryNodes(nodes, edges)
+
+	if len(r.ApplyResults) > 0 {
+		r.annotateApplyResults(nodes)
+	}
+
+	if len(r.Policies) > 0 {
+		r.annotatePolicies(nodes)
+	}
+
+	r.annotateDrift(nodes)
+	r.annotateReplaceReasons(nodes)
+
+	if r.HighlightUnknown {
+		r.annotateUnknown(nodes)
+	}
+
+	if r.GroupByTag != "" {
+		nodes = r.groupByTag(nodes)
+	}
+
+	if r.GenImage {
+		r.labelImageNodes(nodes)
+	}
+
 	r.Graph = Graph{
-		Nodes: nodes,
-		Edges: edges,
+		Nodes:  nodes,
+		Edges:  edges,
+		Layout: r.Layout,
+	}
+
+	if err := r.checkResourceCount(&r.Graph); err != nil {
+		return err
+	}
+
+	if r.CriticalPath {
+		markCriticalPath(&r.Graph)
 	}
 
 	return nil
@@ -138,6 +221,14 @@ func (r *rover) addNodes(base string, parent string, nodeMap map[string]Node, re
 			}
 
 			mrChange := string(re.ChangeAction)
+			if re.Importing {
+				mrChange = fmt.Sprintf("%s import", mrChange)
+			}
+
+			classes := fmt.Sprintf("%s-name %s", re.Type, mrChange)
+			if re.MovedFrom != "" {
+				classes = fmt.Sprintf("%s moved", classes)
+			}
 
 			// Append resource name
 			nmo = append(nmo, id)
@@ -149,8 +240,9 @@ func (r *rover) addNodes(base string, parent string, nodeMap map[string]Node, re
 					Parent:      mid,
 					ParentColor: getResourceColor(nodeMap[parent].Data.Type),
 					Change:      mrChange,
+					MovedFrom:   re.MovedFrom,
 				},
-				Classes: fmt.Sprintf("%s-name %s", re.Type, mrChange),
+				Classes: classes,
 			}
 			//fmt.Printf(id + " - " + mid + "\n")
 
@@ -340,17 +432,60 @@ func (r *rover) GenerateEdges() []Edge {
 
 	emo = append(emo, r.addEdges("", "", edgeMap, r.Map.Root)...)
 
-	edges := make([]Edge, 0, len(edgeMap))
-	exists := make(map[string]bool)
-
+	// Every reference adds its edge ID, so repeated references are counted
+	edges := make([]Edge, 0, len(emo))
 	for _, i := range emo {
-		if _, ok := exists[i]; !ok {
-			edges = append(edges, edgeMap[i])
-			exists[i] = true
+		edges = append(edges, edgeMap[i])
+	}
+
+	return dedupeEdges(edges)
+}
+
+// dedupeEdges collapses edges with the same source, target and classes into a single edge counting them
+func dedupeEdges(edges []Edge) []Edge {
+	type edgeKey struct {
+		source, target, classes string
+	}
+
+	deduped := make([]Edge, 0, len(edges))
+	index := make(map[edgeKey]int)
+
+	for _, e := range edges {
+		key := edgeKey{e.Data.Source, e.Data.Target, e.Classes}
+
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(deduped)
+			deduped = append(deduped, e)
+			continue
 		}
+
+		deduped[i].Data.Count = edgeCount(deduped[i]) + edgeCount(e)
+	}
+
+	return deduped
+}
+
+// edgeCount returns the number of references collapsed into an edge
+func edgeCount(e Edge) int {
+	if e.Data.Count == 0 {
+		return 1
+	}
+	return e.Data.Count
+}
+
+// reverseEdges points every edge from its target to its source
+func reverseEdges(edges []Edge) []Edge {
+	reversed := make([]Edge, 0, len(edges))
+	for _, e := range edges {
+		sourceColor, targetColor, _ := strings.Cut(e.Data.Gradient, " ")
+		e.Data.ID = fmt.Sprintf("%s->%s", e.Data.Target, e.Data.Source)
+		e.Data.Source, e.Data.Target = e.Data.Target, e.Data.Source
+		e.Data.Gradient = fmt.Sprintf("%s %s", targetColor, sourceColor)
+		reversed = append(reversed, e)
 	}
 
-	return edges
+	return reversed
 }
 
 func getResourceColor(t ResourceType) string {
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func edge(source, target, classes string, count int) Edge {
+	return Edge{
+		Data:    EdgeData{ID: source + "->" + target, Source: source, Target: target, Count: count},
+		Classes: classes,
+	}
+}
+
+func TestDedupeEdges(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  []Edge
+	}{
+		{
+			name:  "no edges",
+			edges: []Edge{},
+			want:  []Edge{},
+		},
+		{
+			name: "distinct edges",
+			edges: []Edge{
+				edge("a", "b", "edge", 0),
+				edge("b", "a", "edge", 0),
+				edge("a", "c", "edge", 0),
+			},
+			want: []Edge{
+				edge("a", "b", "edge", 0),
+				edge("b", "a", "edge", 0),
+				edge("a", "c", "edge", 0),
+			},
+		},
+		{
+			name: "redundant references",
+			edges: []Edge{
+				edge("a", "b", "edge", 0),
+				edge("a", "c", "edge", 0),
+				edge("a", "b", "edge", 0),
+				edge("a", "b", "edge", 0),
+			},
+			want: []Edge{
+				edge("a", "b", "edge", 3),
+				edge("a", "c", "edge", 0),
+			},
+		},
+		{
+			name: "different classes aren't collapsed",
+			edges: []Edge{
+				edge("a", "b", "edge", 0),
+				edge("a", "b", "edge critical", 0),
+				edge("a", "b", "edge critical", 0),
+			},
+			want: []Edge{
+				edge("a", "b", "edge", 0),
+				edge("a", "b", "edge critical", 2),
+			},
+		},
+		{
+			name: "counts of collapsed edges add up",
+			edges: []Edge{
+				edge("a", "b", "edge", 2),
+				edge("a", "b", "edge", 0),
+				edge("a", "b", "edge", 3),
+			},
+			want: []Edge{
+				edge("a", "b", "edge", 6),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dedupeEdges(tt.edges)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dedupeEdges() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGenerateGraphRedundantReferences generates the graph of a resource referencing each instance of a
+// counted resource, which references the resource once per instance
+func TestGenerateGraphRedundantReferences(t *testing.T) {
+	r := testRover(t, "redundant.json")
+	if err := r.generateAssets(); err != nil {
+		t.Fatal(err)
+	}
+
+	edges := []Edge{}
+	for _, e := range r.Graph.Edges {
+		if e.Data.Source == "null_resource.c" && e.Data.Target == "null_resource.b" {
+			edges = append(edges, e)
+		}
+	}
+
+	if len(edges) != 1 {
+		t.Fatalf("got %d edges from null_resource.c to null_resource.b, want 1: %+v", len(edges), edges)
+	}
+	if edges[0].Data.Count != 2 {
+		t.Errorf("edge count = %d, want 2", edges[0].Data.Count)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HistoryEntry is the overview of one of the --planHistory plans
+type HistoryEntry struct {
+	Name string `json:"name"`
+	// Timestamp is when Terraform created the plan, if the plan JSON contains it
+	Timestamp string             `json:"timestamp,omitempty"`
+	Summary   Summary            `json:"summary"`
+	RSO       *ResourcesOverview `json:"rso"`
+}
+
+// generateHistory generates an overview of every plan JSON file matching the --planHistory glob,
+// ordered by the timestamp Terraform embeds in plans, or by file name for plans without one
+func (r *rover) generateHistory() error {
+	files, err := filepath.Glob(r.PlanHistory)
+	if err != nil {
+		return fmt.Errorf("invalid --planHistory %s: %s", r.PlanHistory, err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no plans match --planHistory %s", r.PlanHistory)
+	}
+
+	log.Printf("Generating history of %d plans...", len(files))
+
+	history := []HistoryEntry{}
+	for _, file := range files {
+		// Each plan is read into a copy, which gets its own previous addresses
+		h := *r
+		h.PreviousAddresses = map[string]string{}
+		h.ReplaceReasons = map[string]string{}
+
+		h.Plan, err = h.readJSONPlan(file, "")
+		if err != nil {
+			return err
+		}
+
+		if !h.ShowSensitive {
+			h.Plan, err = h.sanitizePlan(h.Plan)
+			if err != nil {
+				return fmt.Errorf("unable to sanitize Plan (%s): %s", file, err)
+			}
+		}
+
+		h.FilterPlan()
+
+		if err := h.GenerateResourceOverview(); err != nil {
+			return err
+		}
+
+		history = append(history, HistoryEntry{
+			Name:      strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+			Timestamp: h.Plan.Timestamp,
+			Summary:   h.summary(),
+			RSO:       h.RSO,
+		})
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		ti, errI := time.Parse(time.RFC3339, history[i].Timestamp)
+		tj, errJ := time.Parse(time.RFC3339, history[j].Timestamp)
+		if errI == nil && errJ == nil && !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return history[i].Name < history[j].Name
+	})
+
+	r.History = history
+
+	return nil
+}
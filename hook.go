@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// runOnComplete runs the --onComplete shell command once assets are generated, with the
+// resource change counts in ROVER_* environment variables. Its output is logged line by line.
+func (r *rover) runOnComplete() error {
+	s := r.summary()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", r.OnComplete)
+	} else {
+		cmd = exec.Command("sh", "-c", r.OnComplete)
+	}
+
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("ROVER_NAME=%s", r.Name),
+		fmt.Sprintf("ROVER_VERSION=%s", VERSION),
+		fmt.Sprintf("ROVER_TOTAL=%d", s.Total),
+		fmt.Sprintf("ROVER_CREATES=%d", s.Create),
+		fmt.Sprintf("ROVER_READS=%d", s.Read),
+		fmt.Sprintf("ROVER_UPDATES=%d", s.Update),
+		fmt.Sprintf("ROVER_DELETES=%d", s.Delete),
+		fmt.Sprintf("ROVER_REPLACES=%d", s.Replace),
+		fmt.Sprintf("ROVER_NOOPS=%d", s.NoOp),
+		fmt.Sprintf("ROVER_IMPORTS=%d", s.Import),
+	)
+
+	log.Printf("Running --onComplete command...")
+	output, err := cmd.CombinedOutput()
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		log.Printf("onComplete: %s", scanner.Text())
+	}
+
+	if err != nil {
+		return fmt.Errorf("--onComplete command failed: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// IMAGE_SUMMARY_MIN_FONT_SIZE keeps the summary banner legible on small images
+const IMAGE_SUMMARY_MIN_FONT_SIZE = 14.0
+
+var (
+	svgTagPattern    = regexp.MustCompile(`<svg[^>]*>`)
+	svgWidthPattern  = regexp.MustCompile(`\swidth="([0-9.]+)(px)?"`)
+	svgHeightPattern = regexp.MustCompile(`\sheight="([0-9.]+)(px)?"`)
+)
+
+// imageSummaryCounts returns the number of resources to add, change and destroy, counted
+// from the RSO like Terraform does, so replaced resources are both added and destroyed
+func (r *rover) imageSummaryCounts() (add, change, destroy int) {
+	if r.RSO == nil {
+		return 0, 0, 0
+	}
+
+	for _, s := range r.RSO.Providers {
+		add += s.Create + s.Replace
+		change += s.Update
+		destroy += s.Delete + s.Replace
+	}
+
+	return add, change, destroy
+}
+
+// addImageSummary adds a banner with the resource change counts like "+12 ~3 -1" to the
+// top of the SVG image, moving the graph below it. The graph is exported by cytoscape
+// without a viewBox, so its width and height are in the same units as its content.
+func (r *rover) addImageSummary(svg []byte) ([]byte, error) {
+	tag := svgTagPattern.Find(svg)
+	if tag == nil {
+		return nil, fmt.Errorf("no <svg> element found")
+	}
+
+	widthMatch := svgWidthPattern.FindSubmatch(tag)
+	heightMatch := svgHeightPattern.FindSubmatch(tag)
+	if widthMatch == nil || heightMatch == nil {
+		return nil, fmt.Errorf("<svg> element has no width or height")
+	}
+	width, _ := strconv.ParseFloat(string(widthMatch[1]), 64)
+	height, _ := strconv.ParseFloat(string(heightMatch[1]), 64)
+
+	fontSize := width / 40
+	if fontSize < IMAGE_SUMMARY_MIN_FONT_SIZE {
+		fontSize = IMAGE_SUMMARY_MIN_FONT_SIZE
+	}
+	bannerHeight := fontSize * 2
+
+	newTag := svgHeightPattern.ReplaceAll(tag, []byte(fmt.Sprintf(` height="%s"`, formatSVGNumber(height+bannerHeight))))
+
+	add, change, destroy := r.imageSummaryCounts()
+
+	var banner bytes.Buffer
+	banner.Write(newTag)
+	fmt.Fprintf(&banner, `<rect x="0" y="0" width="100%%" height="%s" fill="white"/>`, formatSVGNumber(bannerHeight))
+	fmt.Fprintf(&banner, `<text x="%s" y="%s" font-family="sans-serif" font-size="%s" font-weight="bold">`, formatSVGNumber(fontSize/2), formatSVGNumber(fontSize*1.4), formatSVGNumber(fontSize))
+	fmt.Fprintf(&banner, `<tspan fill="%s">+%d</tspan> <tspan fill="%s">~%d</tspan> <tspan fill="%s">-%d</tspan>`, COLOR_ACTION_CREATE, add, COLOR_ACTION_UPDATE, change, COLOR_ACTION_DELETE, destroy)
+	banner.WriteString(`</text>`)
+	fmt.Fprintf(&banner, `<g transform="translate(0,%s)">`, formatSVGNumber(bannerHeight))
+
+	end := bytes.LastIndex(svg, []byte("</svg>"))
+	if end < 0 {
+		return nil, fmt.Errorf("no closing </svg> tag found")
+	}
+
+	start := bytes.Index(svg, tag)
+	out := make([]byte, 0, len(svg)+banner.Len()+len("</g>"))
+	out = append(out, svg[:start]...)
+	out = append(out, banner.Bytes()...)
+	out = append(out, svg[start+len(tag):end]...)
+	out = append(out, "</g>"...)
+	out = append(out, svg[end:]...)
+
+	return out, nil
+}
+
+// writeImageSummary adds the summary banner to the SVG image at path
+func (r *rover) writeImageSummary(path string) error {
+	svg, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read image (%s): %s", path, err)
+	}
+
+	svg, err = r.addImageSummary(svg)
+	if err != nil {
+		return fmt.Errorf("unable to add summary to image (%s): %s", path, err)
+	}
+
+	return os.WriteFile(path, svg, 0644)
+}
+
+func formatSVGNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
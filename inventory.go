@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// INVENTORY_ATTRIBUTES are the resource attributes copied into the inventory when set
+var INVENTORY_ATTRIBUTES = []string{"region", "name"}
+
+// InventoryItem is a managed resource in the planned state
+type InventoryItem struct {
+	Address    string                 `json:"address"`
+	Type       string                 `json:"type"`
+	Name       string                 `json:"name"`
+	Provider   string                 `json:"provider"`
+	Module     string                 `json:"module,omitempty"`
+	Action     Action                 `json:"action,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// inventory lists every managed resource in the planned values, sorted by address
+func (r *rover) inventory() []InventoryItem {
+	items := []InventoryItem{}
+
+	if r.Plan == nil || r.Plan.PlannedValues == nil || r.Plan.PlannedValues.RootModule == nil {
+		return items
+	}
+
+	actions := map[string]Action{}
+	for _, rc := range r.Plan.ResourceChanges {
+		if rc.Change == nil {
+			continue
+		}
+
+		actions[rc.Address] = changeAction(rc.Change.Actions)
+	}
+
+	items = inventoryModule(items, r.Plan.PlannedValues.RootModule, actions)
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Address < items[j].Address
+	})
+
+	return items
+}
+
+func inventoryModule(items []InventoryItem, module *tfjson.StateModule, actions map[string]Action) []InventoryItem {
+	for _, rst := range module.Resources {
+		if rst.Mode != tfjson.ManagedResourceMode {
+			continue
+		}
+
+		item := InventoryItem{
+			Address:  rst.Address,
+			Type:     rst.Type,
+			Name:     rst.Name,
+			Provider: rst.ProviderName,
+			Module:   module.Address,
+			Action:   actions[rst.Address],
+		}
+
+		for _, attribute := range INVENTORY_ATTRIBUTES {
+			if v, ok := rst.AttributeValues[attribute]; ok && v != nil {
+				if item.Attributes == nil {
+					item.Attributes = map[string]interface{}{}
+				}
+				item.Attributes[attribute] = v
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	for _, childModule := range module.ChildModules {
+		items = inventoryModule(items, childModule, actions)
+	}
+
+	return items
+}
+
+// writeInventory saves the resource inventory as JSON to filename
+func (r *rover) writeInventory(filename string) error {
+	b, err := json.MarshalIndent(r.inventory(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error producing inventory JSON: %s", err)
+	}
+
+	if err := os.WriteFile(filename, b, 0644); err != nil {
+		return fmt.Errorf("unable to write inventory (%s): %s", filename, err)
+	}
+
+	log.Printf("Saved resource inventory to %s", filename)
+
+	return nil
+}
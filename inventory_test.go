@@ -0,0 +1,48 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestInventory(t *testing.T) {
+	const aws = "registry.terraform.io/hashicorp/aws"
+
+	r := &rover{Plan: &tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+			Resources: []*tfjson.StateResource{
+				{Address: "aws_instance.web", Mode: tfjson.ManagedResourceMode, Type: "aws_instance", Name: "web", ProviderName: aws, AttributeValues: map[string]interface{}{"region": "eu-west-1"}},
+				{Address: "aws_instance.db", Mode: tfjson.ManagedResourceMode, Type: "aws_instance", Name: "db", ProviderName: aws},
+				{Address: "aws_s3_bucket.logs", Mode: tfjson.ManagedResourceMode, Type: "aws_s3_bucket", Name: "logs", ProviderName: aws},
+				{Address: "data.aws_ami.ubuntu", Mode: tfjson.DataResourceMode, Type: "aws_ami", Name: "ubuntu", ProviderName: aws},
+			},
+			ChildModules: []*tfjson.StateModule{{
+				Address: "module.vpc",
+				Resources: []*tfjson.StateResource{
+					{Address: "module.vpc.aws_vpc.main", Mode: tfjson.ManagedResourceMode, Type: "aws_vpc", Name: "main", ProviderName: aws},
+				},
+			}},
+		}},
+		ResourceChanges: []*tfjson.ResourceChange{
+			resourceChange("aws_instance.web", "aws_instance", aws, tfjson.ActionCreate),
+			resourceChange("aws_instance.db", "aws_instance", aws, tfjson.ActionDelete, tfjson.ActionCreate),
+			// Hand-written or trimmed plans may list a change without actions
+			resourceChange("aws_s3_bucket.logs", "aws_s3_bucket", aws),
+			{Address: "module.vpc.aws_vpc.main", Type: "aws_vpc", Change: &tfjson.Change{Actions: tfjson.Actions{}}},
+		},
+	}}
+
+	want := []InventoryItem{
+		{Address: "aws_instance.db", Type: "aws_instance", Name: "db", Provider: aws, Action: ActionReplace},
+		{Address: "aws_instance.web", Type: "aws_instance", Name: "web", Provider: aws, Action: ActionCreate, Attributes: map[string]interface{}{"region": "eu-west-1"}},
+		{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Name: "logs", Provider: aws, Action: ActionNoop},
+		{Address: "module.vpc.aws_vpc.main", Type: "aws_vpc", Name: "main", Provider: aws, Module: "module.vpc", Action: ActionNoop},
+	}
+
+	got := r.inventory()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("inventory() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+)
+
+const (
+	IMAGE_LABELS_SHORT string = "short"
+	IMAGE_LABELS_FULL  string = "full"
+)
+
+// IMAGE_LABEL_MAX_LENGTH is the number of characters short image labels are truncated to
+const IMAGE_LABEL_MAX_LENGTH = 32
+
+// labelImageNodes sets the labels of the nodes rendered in the generated image. Full labels
+// show the address of resources, modules and other referenceable nodes, short labels are
+// truncated with an ellipsis so long names don't stretch the diagram.
+func (r *rover) labelImageNodes(nodes []Node) {
+	for i, n := range nodes {
+		if r.ImageLabels == IMAGE_LABELS_FULL {
+			if hasAddressLabel(n) {
+				nodes[i].Data.Label = n.Data.ID
+			}
+			continue
+		}
+
+		nodes[i].Data.Label = truncateLabel(n.Data.Label, IMAGE_LABEL_MAX_LENGTH)
+	}
+}
+
+// hasAddressLabel returns true if the node is identified by its address, unlike the
+// file, resource type and base path nodes grouping them
+func hasAddressLabel(n Node) bool {
+	switch n.Data.Type {
+	case ResourceTypeResource, ResourceTypeData:
+		return strings.Contains(n.Classes, "-name")
+	case ResourceTypeModule, ResourceTypeVariable, ResourceTypeOutput, ResourceTypeLocal:
+		return true
+	}
+	return false
+}
+
+// truncateLabel shortens label to at most length characters, ending with an ellipsis if it was cut
+func truncateLabel(label string, length int) string {
+	runes := []rune(label)
+	if len(runes) <= length {
+		return label
+	}
+	return string(runes[:length-1]) + "…"
+}
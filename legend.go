@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+)
+
+// Colors of the graph node styles in ui/src/components/Graph/Graph.vue
+const (
+	COLOR_ACTION_CREATE  string = "#28a745"
+	COLOR_ACTION_UPDATE  string = "#1d7ada"
+	COLOR_ACTION_DELETE  string = "#e40707"
+	COLOR_ACTION_REPLACE string = "#ffc107"
+	COLOR_ACTION_NOOP    string = "lightgray"
+	COLOR_RESOURCE       string = "#8450ba"
+	COLOR_DATA           string = "#ffecec"
+	COLOR_DATA_BORDER    string = "#dc477d"
+)
+
+// LegendEntry explains the style of a kind of graph node
+type LegendEntry struct {
+	Label  string `json:"label"`
+	Color  string `json:"color"`
+	Border string `json:"border,omitempty"`
+	Shape  string `json:"shape"`
+}
+
+// Legend explains what the colors and shapes of the graph nodes mean
+type Legend struct {
+	Actions []LegendEntry `json:"actions"`
+	Types   []LegendEntry `json:"types"`
+}
+
+// graphLegend returns the legend of the graph node styles
+func graphLegend() Legend {
+	return Legend{
+		Actions: []LegendEntry{
+			{Label: string(ActionCreate), Color: COLOR_ACTION_CREATE, Shape: "roundrectangle"},
+			{Label: string(ActionUpdate), Color: COLOR_ACTION_UPDATE, Shape: "roundrectangle"},
+			{Label: string(ActionDelete), Color: COLOR_ACTION_DELETE, Shape: "roundrectangle"},
+			{Label: string(ActionReplace), Color: COLOR_ACTION_REPLACE, Shape: "roundrectangle"},
+			{Label: string(ActionNoop), Color: "white", Border: COLOR_ACTION_NOOP, Shape: "roundrectangle"},
+		},
+		Types: []LegendEntry{
+			{Label: string(ResourceTypeResource), Color: COLOR_RESOURCE, Shape: "roundrectangle"},
+			{Label: string(ResourceTypeData), Color: COLOR_DATA, Border: COLOR_DATA_BORDER, Shape: "roundrectangle"},
+			{Label: string(ResourceTypeModule), Color: "white", Border: COLOR_RESOURCE, Shape: "roundrectangle"},
+		},
+	}
+}
+
+// exportFrontendConfig returns the frontend config of standalone exports, which embeds the
+// legend so recipients always see what the node styles mean
+func (r *rover) exportFrontendConfig() (json.RawMessage, error) {
+	config, err := r.frontendConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(config, &fields); err != nil {
+		return nil, fmt.Errorf("invalid config JSON: %s", err)
+	}
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	fields["legend"] = graphLegend()
+	// Exports aren't served by Rover, so there's nothing to post events to
+	delete(fields, "eventLog")
+
+	return json.Marshal(fields)
+}
+
+// addImageLegend adds a row with the legend of the node styles to the bottom of the SVG image
+func addImageLegend(svg []byte) ([]byte, error) {
+	tag := svgTagPattern.Find(svg)
+	if tag == nil {
+		return nil, fmt.Errorf("no <svg> element found")
+	}
+
+	widthMatch := svgWidthPattern.FindSubmatch(tag)
+	heightMatch := svgHeightPattern.FindSubmatch(tag)
+	if widthMatch == nil || heightMatch == nil {
+		return nil, fmt.Errorf("<svg> element has no width or height")
+	}
+	width, _ := strconv.ParseFloat(string(widthMatch[1]), 64)
+	height, _ := strconv.ParseFloat(string(heightMatch[1]), 64)
+
+	fontSize := width / 60
+	if fontSize < IMAGE_SUMMARY_MIN_FONT_SIZE {
+		fontSize = IMAGE_SUMMARY_MIN_FONT_SIZE
+	}
+	legendHeight := fontSize * 2
+
+	newTag := svgHeightPattern.ReplaceAll(tag, []byte(fmt.Sprintf(` height="%s"`, formatSVGNumber(height+legendHeight))))
+
+	legend := graphLegend()
+	var row bytes.Buffer
+	fmt.Fprintf(&row, `<rect x="0" y="%s" width="100%%" height="%s" fill="white"/>`, formatSVGNumber(height), formatSVGNumber(legendHeight))
+	x := fontSize / 2
+	for _, e := range append(legend.Actions, legend.Types...) {
+		border := e.Border
+		if border == "" {
+			border = e.Color
+		}
+		fmt.Fprintf(&row, `<rect x="%s" y="%s" width="%s" height="%s" rx="%s" fill="%s" stroke="%s" stroke-width="2"/>`,
+			formatSVGNumber(x), formatSVGNumber(height+fontSize/2), formatSVGNumber(fontSize), formatSVGNumber(fontSize),
+			formatSVGNumber(fontSize/4), e.Color, border)
+		x += fontSize * 1.5
+		fmt.Fprintf(&row, `<text x="%s" y="%s" font-family="sans-serif" font-size="%s">%s</text>`,
+			formatSVGNumber(x), formatSVGNumber(height+fontSize*1.35), formatSVGNumber(fontSize), html.EscapeString(e.Label))
+		// Approximate the label width, since the SVG isn't rendered here
+		x += fontSize * (0.6*float64(len(e.Label)) + 1.5)
+	}
+
+	end := bytes.LastIndex(svg, []byte("</svg>"))
+	if end < 0 {
+		return nil, fmt.Errorf("no closing </svg> tag found")
+	}
+
+	start := bytes.Index(svg, tag)
+	out := make([]byte, 0, len(svg)+len(newTag)-len(tag)+row.Len())
+	out = append(out, svg[:start]...)
+	out = append(out, newTag...)
+	out = append(out, svg[start+len(tag):end]...)
+	out = append(out, row.Bytes()...)
+	out = append(out, svg[end:]...)
+
+	return out, nil
+}
+
+// writeImageLegend adds the legend to the SVG image at path
+func writeImageLegend(path string) error {
+	svg, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read image (%s): %s", path, err)
+	}
+
+	svg, err = addImageLegend(svg)
+	if err != nil {
+		return fmt.Errorf("unable to add legend to image (%s): %s", path, err)
+	}
+
+	return os.WriteFile(path, svg, 0644)
+}
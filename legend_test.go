@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExportFrontendConfig(t *testing.T) {
+	r := &rover{EventLog: "events.jsonl", StaticLayout: true}
+
+	config, err := r.exportFrontendConfig()
+	if err != nil {
+		t.Fatalf("exportFrontendConfig() error = %s", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(config, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["legend"]; !ok {
+		t.Error("exported frontend config has no legend")
+	}
+	if fields["staticLayout"] != true {
+		t.Errorf("staticLayout = %v, want true", fields["staticLayout"])
+	}
+	// Exports can't post events to the Rover that generated them
+	if _, ok := fields["eventLog"]; ok {
+		t.Error("exported frontend config enables eventLog")
+	}
+}
@@ -1,8 +1,8 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"embed"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,69 +12,189 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/akamensky/argparse"
-	tfe "github.com/hashicorp/go-tfe"
 	"github.com/hashicorp/terraform-exec/tfexec"
 	tfjson "github.com/hashicorp/terraform-json"
-	"github.com/hashicorp/terraform-json/sanitize"
 )
 
 const VERSION = "0.4.3"
 
+// TF_VAR_ENV_PREFIX prefixes the environment variables Terraform reads input variables from
+const TF_VAR_ENV_PREFIX = "TF_VAR_"
+
 var TRUE = true
 
-//go:embed ui/dist
-var frontend embed.FS
+// Directories relative --planPath and --planJSONPath are resolved against
+const (
+	PLAN_PATH_RELATIVE_TO_CWD         string = "cwd"
+	PLAN_PATH_RELATIVE_TO_WORKING_DIR string = "workingDir"
+)
 
-type arrayFlags []string
+// FRONTEND_NOT_BUILT explains how to build a binary embedding the frontend
+const FRONTEND_NOT_BUILT = "frontend not built, run `npm install && npm run build` in ui and rebuild Rover"
 
-func (i arrayFlags) String() string {
-	var ts []string
-	for _, el := range i {
-		ts = append(ts, el)
+// embeddedFrontend returns the frontend embedded in the binary, and false if it was built
+// without building the frontend first
+func embeddedFrontend() (fs.FS, bool) {
+	fe, err := fs.Sub(frontend, "ui/dist")
+	if err != nil {
+		return nil, false
+	}
+	if _, err := fs.Stat(fe, "index.html"); err != nil {
+		return fe, false
 	}
-	return strings.Join(ts, ",")
+	return fe, true
 }
 
-func (i *arrayFlags) Set(value string) error {
-	*i = append(*i, value)
-	return nil
+// parsePositiveDuration parses a duration such as 10s, rejecting zero and negative durations
+func parsePositiveDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive, got %s", s)
+	}
+	return d, nil
 }
 
 type rover struct {
-	Name             string
-	WorkingDir       string
-	TfPath           string
-	TfVarsFiles      []string
-	TfVars           []string
-	TfBackendConfigs []string
-	PlanPath         string
-	PlanJSONPath     string
-	WorkspaceName    string
-	TFCOrgName       string
-	TFCWorkspaceName string
-	ShowSensitive    bool
-	GenImage         bool
-	TFCNewRun        bool
-	Plan             *tfjson.Plan
-	RSO              *ResourcesOverview
-	Map              *Map
-	Graph            Graph
+	Name              string
+	WorkingDir        string
+	TfPath            string
+	Engine            Engine
+	TfVersion         string
+	TfVarsFiles       []string
+	TfVars            []string
+	TfVarsOrder       []bool
+	TfBackendConfigs  []string
+	PlanPath          string
+	PlanJSONPath      string
+	StateJSONPath     string
+	WorkspaceNames    []string
+	TFCOrgName        string
+	TFCWorkspaceNames []string
+	TFCWorkspaceTag   string
+	ShowSensitive     bool
+	GenImage          bool
+	TFCNewRun         bool
+	ModuleFocus       string
+	PinModules        []string
+	ChangesOnly       bool
+	NotifyURL         string
+	NotifyTimeout     time.Duration
+	NotifyRetries     int
+	CriticalPath      bool
+	MaxResources      int
+	OnOverflow        string
+	ZipFileName       string
+	VerboseErrors     bool
+	Anonymize         bool
+	AnonymizeMapFile  string
+	KeepPlan          string
+	Profile           bool
+	PlanPrefix        string
+	SanitizeMode      string
+	EdgeDirection     string
+	TFCPollInterval   time.Duration
+	TFCPlanDelay      time.Duration
+	TFCInsecure       bool
+	ExpandEnvVars     bool
+	TFCNoCancel       bool
+	PlanJSONFormat    string
+	ShowOutputs       bool
+	FrontendConfig    json.RawMessage
+	Layout            string
+	MaxModuleDepth    int
+	OnlyActions       map[Action]bool
+	OnlyResources     []*regexp.Regexp
+	ExcludeResources  []*regexp.Regexp
+	// FilteredResources are the addresses of the resources filtered out of the plan with all of their instances
+	FilteredResources map[string]bool
+	// ModulesTruncated is set if modules nested deeper than --maxModuleDepth were left out of the plan
+	ModulesTruncated  bool
+	IgnoreInitErrors  bool
+	ImageLabels       string
+	Lock              bool
+	OutputDir         string
+	TFCSince          time.Time
+	ShowApplyResult   bool
+	ShowPolicies      bool
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxUploadBytes    int64
+	AllowRefresh      bool
+	AllowUpload       bool
+	RateLimit         float64
+	ImageSummary      bool
+	ImageLegend       bool
+	CollapseInstances bool
+	StaticLayout      bool
+	EventLog          string
+	HighlightUnknown  bool
+	OnComplete        string
+	CLIConfigFile     string
+	TfEnvVars         map[string]string
+	TfPlanOptions     []tfexec.PlanOption
+	RelativePaths     bool
+	APIOnly           bool
+	GroupByTag        string
+	InfracostPath     string
+	PlanHistory       string
+	Plan              *tfjson.Plan
+	RSO               *ResourcesOverview
+	Map               *Map
+	Graph             Graph
+	Diagnostics       []Diagnostic
+	// History is the overview of every --planHistory plan, oldest first
+	History []HistoryEntry
+	// GeneratedAt is when the assets were last generated, used for ETags of the API responses
+	GeneratedAt time.Time
+	// Policies are the policy checks of the Terraform Cloud runs, with --showPolicies
+	Policies []PolicyCheck
+	// ApplyResults maps the addresses of applied resources to their outcome, with --showApplyResult
+	ApplyResults map[string]string
+	// PreviousAddresses maps the addresses of resources moved by moved blocks to their previous address
+	PreviousAddresses map[string]string
+	// ReplaceReasons maps the addresses of replaced resources to why they are replaced
+	ReplaceReasons map[string]string
+	// Manifest lists the files generated so far, printed with --stdout
+	Manifest Manifest
+
+	// Guards Plan, RSO, Map, Graph, Diagnostics, History, Policies, ApplyResults, PreviousAddresses,
+	// ReplaceReasons, GeneratedAt and TfVersion once the server is running.
+	// Refresh replaces them as a set under the write lock and never modifies them in place,
+	// so HTTP handlers must read them from snapshot() instead of from the rover directly.
+	assetsMu *sync.RWMutex
+	// Held while assets are refreshed
+	refreshMu *sync.Mutex
+	// Broadcasts the progress of generating the assets to websocket clients
+	progress *progressHub
+	// Plan JSON posted to /api/upload with --allowUpload, used instead of getting a plan while refreshing
+	uploadedPlan []byte
 }
 
 func main() {
-	var tfPath, workingDir, name, zipFileName, ipPort, planPathPtr, planJSONPathPtr, workspaceName, tfcOrgName, tfcWorkspaceName *string
-	var standalone, genImage, showSensitive, getVersion, tfcNewRun *bool
-	var tfVarsFiles, tfVars, tfBackendConfigs arrayFlags
+	var tfPath, workingDir, name, zipFileName, ipPort, planPathPtr, planJSONPathPtr, tfcOrgName, moduleFocus, engine *string
+	var standalone, genImage, showSensitive, getVersion, tfcNewRun, noColor, changesOnly, criticalPath, verboseErrors *bool
 
 	parser := argparse.NewParser("rover", "Rover is a Terraform visualizer")
 	tfPath = parser.String("", "tfPath", &argparse.Options{
 		Required: false,
-		Help:     "Path to Terraform binary",
-		Default:  "/bin/terraform",
+		Help:     "Path to Terraform binary (defaults to /bin/<engine>, or <engine> in PATH if missing)",
+		Default:  "",
+	})
+	engine = parser.Selector("", "engine", []string{string(EngineTerraform), string(EngineOpenTofu)}, &argparse.Options{
+		Required: false,
+		Help:     "Binary used to generate plans (terraform or tofu)",
+		Default:  string(EngineTerraform),
 	})
 	workingDir = parser.String("", "workingDir", &argparse.Options{
 		Required: false,
@@ -86,6 +206,16 @@ func main() {
 		Help:     "Configuration name",
 		Default:  "rover",
 	})
+	outputDir := parser.String("", "outputDir", &argparse.Options{
+		Required: false,
+		Help:     "Directory to write the standalone zip, generated image and --debug JSON files to, created if missing",
+		Default:  "",
+	})
+	relativePaths := parser.Flag("", "relativePaths", &argparse.Options{
+		Required: false,
+		Help:     "Make the configuration file paths in the generated assets relative to the working directory",
+		Default:  false,
+	})
 	zipFileName = parser.String("", "zipFileName", &argparse.Options{
 		Required: false,
 		Help:     "Standalone zip file name",
@@ -93,9 +223,14 @@ func main() {
 	})
 	ipPort = parser.String("", "ipPort", &argparse.Options{
 		Required: false,
-		Help:     "IP and port for Rover server",
+		Help:     "IP and port for Rover server, port 0 binds to a free port",
 		Default:  "0.0.0.0:9000",
 	})
+	apiOnly := parser.Flag("", "apiOnly", &argparse.Options{
+		Required: false,
+		Help:     "Serve only the API, without the frontend",
+		Default:  false,
+	})
 	planPathPtr = parser.String("", "planPath", &argparse.Options{
 		Required: false,
 		Help:     "Plan file path",
@@ -103,22 +238,57 @@ func main() {
 	})
 	planJSONPathPtr = parser.String("", "planJSONPath", &argparse.Options{
 		Required: false,
-		Help:     "Plan JSON file path",
+		Help:     "Plan JSON file path, a directory of plan JSON files to merge, or an s3:// or gs:// URL",
 		Default:  "",
 	})
-	workspaceName = parser.String("", "workspaceName", &argparse.Options{
+	planPathRelativeTo := parser.Selector("", "planPathRelativeTo", []string{PLAN_PATH_RELATIVE_TO_CWD, PLAN_PATH_RELATIVE_TO_WORKING_DIR}, &argparse.Options{
+		Required: false,
+		Help:     "Resolve relative --planPath, --planJSONPath and --stateJSONPath against the current directory or --workingDir",
+		Default:  PLAN_PATH_RELATIVE_TO_CWD,
+	})
+	stateJSONPathPtr := parser.String("", "stateJSONPath", &argparse.Options{
 		Required: false,
-		Help:     "Workspace name",
+		Help:     "State JSON file path (terraform show -json output without a plan) to visualize the current infrastructure",
 		Default:  "",
 	})
+	planHistory := parser.String("", "planHistory", &argparse.Options{
+		Required: false,
+		Help:     "Glob of plan JSON files whose overviews are served in order at /api/history, to show how the planned changes evolved",
+		Default:  "",
+	})
+	planJSONFormat := parser.Selector("", "planJSONFormat", []string{PLAN_JSON_FORMAT_RAW, PLAN_JSON_FORMAT_TFC, PLAN_JSON_FORMAT_ATLANTIS}, &argparse.Options{
+		Required: false,
+		Help:     "Envelope the plan JSON is wrapped in (raw, tfc or atlantis)",
+		Default:  PLAN_JSON_FORMAT_RAW,
+	})
+	workspaceNames := parser.StringList("", "workspaceName", &argparse.Options{
+		Required: false,
+		Help:     "Workspace name (repeatable to merge the plans of several workspaces)",
+		Default:  []string{},
+	})
 	tfcOrgName = parser.String("", "tfcOrg", &argparse.Options{
 		Required: false,
 		Help:     "Terraform Cloud Organization name",
 		Default:  "",
 	})
-	tfcWorkspaceName = parser.String("", "tfcWorkspace", &argparse.Options{
+	tfcWorkspaceNames := parser.StringList("", "tfcWorkspace", &argparse.Options{
 		Required: false,
-		Help:     "Terraform Cloud Workspace name",
+		Help:     "Terraform Cloud Workspace name (repeatable to merge workspaces)",
+		Default:  []string{},
+	})
+	tfcWorkspaceTag := parser.String("", "tfcWorkspaceTag", &argparse.Options{
+		Required: false,
+		Help:     "Merge the plans of all Terraform Cloud Workspaces with this tag",
+		Default:  "",
+	})
+	moduleFocus = parser.String("", "moduleFocus", &argparse.Options{
+		Required: false,
+		Help:     "Only visualize the given module address and its children",
+		Default:  "",
+	})
+	pinModules := parser.String("", "pinModules", &argparse.Options{
+		Required: false,
+		Help:     "Keep these module addresses expanded in the map and collapse the rest, comma-separated",
 		Default:  "",
 	})
 	standalone = parser.Flag("", "standalone", &argparse.Options{
@@ -126,6 +296,46 @@ func main() {
 		Help:     "Generate standalone HTML files",
 		Default:  false,
 	})
+	format := parser.Selector("", "format", []string{FORMAT_ZIP, FORMAT_CYTOSCAPE, FORMAT_CSV}, &argparse.Options{
+		Required: false,
+		Help:     "Standalone output format: zip of the frontend and assets, the graph as Cytoscape.js JSON, or the graph as nodes.csv and edges.csv",
+		Default:  FORMAT_ZIP,
+	})
+	changesOnly = parser.Flag("", "changesOnly", &argparse.Options{
+		Required: false,
+		Help:     "Exclude resources without changes (no-op)",
+		Default:  false,
+	})
+	onlyActions := parser.String("", "onlyActions", &argparse.Options{
+		Required: false,
+		Help:     "Only include resources with these change actions, comma-separated (e.g. delete,replace)",
+		Default:  "",
+	})
+	excludeResources := parser.StringList("", "excludeResource", &argparse.Options{
+		Required: false,
+		Help:     "Exclude resources with this address, in which * matches any characters (repeatable)",
+		Default:  []string{},
+	})
+	onlyResources := parser.StringList("", "onlyResource", &argparse.Options{
+		Required: false,
+		Help:     "Only include resources with this address, in which * matches any characters (repeatable)",
+		Default:  []string{},
+	})
+	ignoreInitErrors := parser.Flag("", "ignoreInitErrors", &argparse.Options{
+		Required: false,
+		Help:     "Warn instead of failing if init fails in a previously initialized working directory, and plan with the providers and modules that init installed",
+		Default:  false,
+	})
+	lock := parser.Selector("", "lock", []string{"true", "false"}, &argparse.Options{
+		Required: false,
+		Help:     "Lock the state while planning, false lets concurrent read-only plans run",
+		Default:  "true",
+	})
+	criticalPath = parser.Flag("", "criticalPath", &argparse.Options{
+		Required: false,
+		Help:     "Highlight the longest dependency chain in the graph",
+		Default:  false,
+	})
 	showSensitive = parser.Flag("", "showSensitive", &argparse.Options{
 		Required: false,
 		Help:     "Display sensitive values",
@@ -136,6 +346,41 @@ func main() {
 		Help:     "Create new Terraform Cloud run",
 		Default:  false,
 	})
+	tfcNoCancel := parser.Flag("", "tfcNoCancel", &argparse.Options{
+		Required: false,
+		Help:     "Don't cancel the new Terraform Cloud run when Rover is interrupted or times out waiting for it",
+		Default:  false,
+	})
+	tfcSince := parser.String("", "tfcSince", &argparse.Options{
+		Required: false,
+		Help:     "Use the newest Terraform Cloud run created on or before this RFC3339 timestamp",
+		Default:  "",
+	})
+	showApplyResult := parser.Flag("", "showApplyResult", &argparse.Options{
+		Required: false,
+		Help:     "Mark resources with their apply outcome if the Terraform Cloud run was applied",
+		Default:  false,
+	})
+	showPolicies := parser.Flag("", "showPolicies", &argparse.Options{
+		Required: false,
+		Help:     "Fetch the policy checks of the Terraform Cloud run and mark resources failing them",
+		Default:  false,
+	})
+	tfcInsecure := parser.Flag("", "tfcInsecure", &argparse.Options{
+		Required: false,
+		Help:     "Skip TLS certificate verification for Terraform Cloud connections (insecure)",
+		Default:  false,
+	})
+	noColor = parser.Flag("", "noColor", &argparse.Options{
+		Required: false,
+		Help:     "Disable colored log output",
+		Default:  false,
+	})
+	verboseErrors = parser.Flag("", "verboseErrors", &argparse.Options{
+		Required: false,
+		Help:     "Display the full output of failed Terraform commands",
+		Default:  false,
+	})
 	getVersion = parser.Flag("", "version", &argparse.Options{
 		Required: false,
 		Help:     "Get current version",
@@ -146,116 +391,762 @@ func main() {
 		Help:     "Generate graph image",
 		Default:  false,
 	})
-	tfVarsFilesTmp := parser.StringList("", "tfVarsFile", &argparse.Options{
+	notifyURL := parser.String("", "notifyURL", &argparse.Options{
+		Required: false,
+		Help:     "URL to POST to once assets are generated",
+		Default:  "",
+	})
+	infracostPath := parser.String("", "infracostPath", &argparse.Options{
+		Required: false,
+		Help:     "Infracost binary to estimate the costs of the plan with, skipped if not found",
+		Default:  "",
+	})
+	onComplete := parser.String("", "onComplete", &argparse.Options{
+		Required: false,
+		Help:     "Shell command to run once assets are generated, with the change counts in ROVER_CREATES, ROVER_DELETES, etc.",
+		Default:  "",
+	})
+	onCompleteIgnoreError := parser.Flag("", "onCompleteIgnoreError", &argparse.Options{
+		Required: false,
+		Help:     "Continue if the --onComplete command fails",
+		Default:  false,
+	})
+	notifyTimeout := parser.String("", "notifyTimeout", &argparse.Options{
+		Required: false,
+		Help:     "Timeout for each notification request",
+		Default:  "10s",
+	})
+	notifyRetries := parser.Int("", "notifyRetries", &argparse.Options{
+		Required: false,
+		Help:     "Number of times to retry a notification failing with a network or server error",
+		Default:  3,
+	})
+	anonymize := parser.Flag("", "anonymize", &argparse.Options{
+		Required: false,
+		Help:     "Replace resource, module, variable and output names and string values with pseudonyms",
+		Default:  false,
+	})
+	anonymizeMapFile := parser.String("", "anonymizeMapFile", &argparse.Options{
+		Required: false,
+		Help:     "File to save the pseudonym to name mapping to when anonymizing, relative to --outputDir",
+		Default:  "rover-anonymize-map.json",
+	})
+	cliConfigFile := parser.String("", "cliConfigFile", &argparse.Options{
+		Required: false,
+		Help:     "CLI config file with provider mirrors or credentials, set as TF_CLI_CONFIG_FILE for init and plan",
+		Default:  "",
+	})
+	keepPlan := parser.String("", "keepPlan", &argparse.Options{
+		Required: false,
+		Help:     "File or directory to save the generated plan file to",
+		Default:  "",
+	})
+	profile := parser.Flag("", "profile", &argparse.Options{
+		Required: false,
+		Help:     "Write CPU and heap profiles of asset generation and serve /debug/pprof/",
+		Default:  false,
+	})
+	planPrefix := parser.String("", "planPrefix", &argparse.Options{
+		Required: false,
+		Help:     "Name prefix of the generated plan file",
+		Default:  "roverplan",
+	})
+	sanitizeMode := parser.Selector("", "sanitizeMode", []string{SANITIZE_MODE_FULL, SANITIZE_MODE_PRESERVE_SHAPE}, &argparse.Options{
+		Required: false,
+		Help:     "How sensitive values are masked (full or redact-preserve-shape)",
+		Default:  SANITIZE_MODE_FULL,
+	})
+	maxModuleDepth := parser.Int("", "maxModuleDepth", &argparse.Options{
+		Required: false,
+		Help:     "Maximum module nesting depth of the plan, deeper modules are left out (0 for no limit)",
+		Default:  100,
+	})
+	maxResources := parser.Int("", "maxResources", &argparse.Options{
+		Required: false,
+		Help:     "Maximum number of resources to visualize (0 for no limit)",
+		Default:  5000,
+	})
+	onOverflow := parser.Selector("", "onOverflow", []string{OVERFLOW_WARN, OVERFLOW_ERROR, OVERFLOW_COLLAPSE}, &argparse.Options{
+		Required: false,
+		Help:     "What to do when the plan exceeds --maxResources (warn, error or collapse modules in the graph)",
+		Default:  OVERFLOW_WARN,
+	})
+	edgeDirection := parser.Selector("", "edgeDirection", []string{EDGE_DIRECTION_DOWNSTREAM, EDGE_DIRECTION_UPSTREAM}, &argparse.Options{
+		Required: false,
+		Help:     "Graph edge orientation (downstream points from a resource to its dependencies, upstream from dependencies to their dependents)",
+		Default:  EDGE_DIRECTION_DOWNSTREAM,
+	})
+	inventory := parser.String("", "inventory", &argparse.Options{
+		Required: false,
+		Help:     "File to save a JSON inventory of the planned resources to",
+		Default:  "",
+	})
+	tfcPollInterval := parser.String("", "tfcPollInterval", &argparse.Options{
+		Required: false,
+		Help:     "Time between polls while waiting for a new Terraform Cloud run",
+		Default:  "10s",
+	})
+	tfcPlanDelay := parser.String("", "tfcPlanDelay", &argparse.Options{
+		Required: false,
+		Help:     "Extra time to wait for the plan JSON once a new Terraform Cloud plan is finished (default none)",
+		Default:  "",
+	})
+	readTimeout := parser.String("", "readTimeout", &argparse.Options{
+		Required: false,
+		Help:     "Maximum duration for the server to read a request",
+		Default:  "30s",
+	})
+	writeTimeout := parser.String("", "writeTimeout", &argparse.Options{
+		Required: false,
+		Help:     "Maximum duration for the server to write a response, including refreshing the plan",
+		Default:  "10m",
+	})
+	idleTimeout := parser.String("", "idleTimeout", &argparse.Options{
+		Required: false,
+		Help:     "Maximum duration to keep idle keep-alive connections open",
+		Default:  "2m",
+	})
+	allowRefresh := parser.Flag("", "allowRefresh", &argparse.Options{
+		Required: false,
+		Help:     "Serve /api/refresh, which generates a new plan on request",
+		Default:  false,
+	})
+	allowUpload := parser.Flag("", "allowUpload", &argparse.Options{
+		Required: false,
+		Help:     "Serve /api/upload, which replaces the visualization with a posted plan JSON",
+		Default:  false,
+	})
+	maxUploadBytes := parser.Int("", "maxUploadBytes", &argparse.Options{
+		Required: false,
+		Help:     "Maximum size of a plan JSON posted to /api/upload",
+		Default:  50 << 20,
+	})
+	rateLimit := parser.Float("", "rateLimit", &argparse.Options{
+		Required: false,
+		Help:     "Maximum requests per second of each client IP, 0 to disable",
+		Default:  0.0,
+	})
+	configs := parser.StringList("", "config", &argparse.Options{
+		Required: false,
+		Help:     "Named configuration to serve under /<name>/ (name=workingDir), can be repeated to serve several",
+		Default:  []string{},
+	})
+	stats := parser.Flag("", "stats", &argparse.Options{
+		Required: false,
+		Help:     "Print plan statistics as tables and exit",
+		Default:  false,
+	})
+	debug := parser.Flag("", "debug", &argparse.Options{
+		Required: false,
+		Help:     "Save the plan, rso, map and graph as JSON files to --outputDir for troubleshooting",
+		Default:  false,
+	})
+	stdoutManifest := parser.Flag("", "stdout", &argparse.Options{
+		Required: false,
+		Help:     "Print a JSON manifest of the generated files to stdout, with --standalone or --genImage",
+		Default:  false,
+	})
+	treeOutput := parser.Flag("", "treeOutput", &argparse.Options{
+		Required: false,
+		Help:     "Print the module and resource hierarchy as a tree and exit",
+		Default:  false,
+	})
+	showOutputs := parser.Flag("", "showOutputs", &argparse.Options{
+		Required: false,
+		Help:     "Show the planned output values and their changes",
+		Default:  false,
+	})
+	configURL := parser.String("", "configURL", &argparse.Options{
+		Required: false,
+		Help:     "URL of a JSON frontend config (e.g. theming) to fetch at startup and serve at /config.json",
+		Default:  "",
+	})
+	highlightUnknown := parser.Flag("", "highlightUnknown", &argparse.Options{
+		Required: false,
+		Help:     "Mark the resources and attributes with values only known after apply in the graph and /api/resource",
+		Default:  false,
+	})
+	staticLayout := parser.Flag("", "staticLayout", &argparse.Options{
+		Required: false,
+		Help:     "Ask the frontend to render the graph without animations, set as staticLayout in /config.json",
+		Default:  false,
+	})
+	eventLog := parser.String("", "eventLog", &argparse.Options{
+		Required: false,
+		Help:     "Append interaction events the frontend posts to /api/event to this JSONL file",
+		Default:  "",
+	})
+	imageSummary := parser.Flag("", "imageSummary", &argparse.Options{
+		Required: false,
+		Help:     "Add a banner with the number of resources to add, change and destroy to the generated image",
+		Default:  false,
+	})
+	imageLegend := parser.Flag("", "imageLegend", &argparse.Options{
+		Required: false,
+		Help:     "Add a legend of the node colors and shapes to the generated image",
+		Default:  false,
+	})
+	groupByTag := parser.String("", "groupByTag", &argparse.Options{
+		Required: false,
+		Help:     "Group the resources in the graph by the value of this tag (e.g. environment), or untagged",
+		Default:  "",
+	})
+	collapseInstances := parser.Flag("", "collapseInstances", &argparse.Options{
+		Required: false,
+		Help:     "Collapse the count and for_each instances of each resource into a single graph node",
+		Default:  false,
+	})
+	imageLabels := parser.Selector("", "imageLabels", []string{IMAGE_LABELS_SHORT, IMAGE_LABELS_FULL}, &argparse.Options{
+		Required: false,
+		Help:     "Node labels in the generated image: short names truncated with an ellipsis, or full addresses",
+		Default:  IMAGE_LABELS_SHORT,
+	})
+	layout := parser.Selector("", "layout", []string{LAYOUT_LEFT_RIGHT, LAYOUT_TOP_BOTTOM}, &argparse.Options{
+		Required: false,
+		Help:     "Graph layout direction (LR for left to right, TB for top to bottom)",
+		Default:  LAYOUT_LEFT_RIGHT,
+	})
+	checkOnly := parser.Flag("", "checkOnly", &argparse.Options{
+		Required: false,
+		Help:     "Check the inputs are usable and exit without generating a plan",
+		Default:  false,
+	})
+	tfVarsFiles := parser.StringList("", "tfVarsFile", &argparse.Options{
 		Required: false,
 		Help:     "Path to *.tfvars files",
 		Default:  []string{},
 	})
-	tfVarsTmp := parser.StringList("", "tfVar", &argparse.Options{
+	expandEnvVars := parser.Flag("", "expandEnvVars", &argparse.Options{
+		Required: false,
+		Help:     "Expand ${VAR} environment variable references in tfvars files",
+		Default:  false,
+	})
+	tfVars := parser.StringList("", "tfVar", &argparse.Options{
 		Required: false,
 		Help:     "Terraform variable (key=value)",
 		Default:  []string{},
 	})
-	tfBackendConfigsTmp := parser.StringList("", "tfBackendConfig", &argparse.Options{
+	tfEnvVars := parser.StringList("", "tfEnvVar", &argparse.Options{
+		Required: false,
+		Help:     "Terraform variable set as a TF_VAR_ environment variable (key=value, repeatable)",
+		Default:  []string{},
+	})
+	tfPlanArgs := parser.StringList("", "tfPlanArg", &argparse.Options{
+		Required: false,
+		Help:     "Extra terraform plan flag, with or without the leading dash (e.g. refresh=false, repeatable)",
+		Default:  []string{},
+	})
+	tfBackendConfigs := parser.StringList("", "tfBackendConfig", &argparse.Options{
 		Required: false,
-		Help:     "Path to *.tfbackend files",
+		Help:     "Path to *.tfbackend files or backend config (key=value)",
 		Default:  []string{},
 	})
 
-	err := parser.Parse(os.Args)
-	if err != nil {
-		fmt.Print(parser.Usage(err))
+	err := parser.Parse(os.Args)
+	if err != nil {
+		fmt.Print(parser.Usage(err))
+	}
+
+	if *getVersion {
+		if Engine(*engine) == EngineOpenTofu {
+			fmt.Printf("Rover v%s (%s)\n", VERSION, EngineOpenTofu.Name())
+		} else {
+			fmt.Printf("Rover v%s\n", VERSION)
+		}
+		return
+	}
+
+	setupColor(*noColor)
+
+	logStatus(COLOR_CYAN, "Starting Rover...")
+
+	// Each plan source replaces the others, so only one of them can be given
+	planSources := []string{}
+	for _, source := range []struct {
+		flag string
+		set  bool
+	}{
+		{"--planPath", *planPathPtr != ""},
+		{"--planJSONPath", *planJSONPathPtr != ""},
+		{"--stateJSONPath", *stateJSONPathPtr != ""},
+		{"--tfcWorkspace", len(*tfcWorkspaceNames) > 0},
+		{"--tfcWorkspaceTag", *tfcWorkspaceTag != ""},
+	} {
+		if source.set {
+			planSources = append(planSources, source.flag)
+		}
+	}
+	if len(planSources) > 1 {
+		log.Fatalf("%s can't be combined with %s", planSources[0], strings.Join(planSources[1:], " or "))
+	}
+
+	// Fall back to the environment variables used by other Terraform tooling. They're ignored if another
+	// plan source is given, so exporting them in CI doesn't send local plans to Terraform Cloud.
+	localPlan := *planPathPtr != "" || *planJSONPathPtr != "" || *stateJSONPathPtr != ""
+	if *tfcOrgName != "" {
+		log.Printf("Using Terraform Cloud organization %s from --tfcOrg", *tfcOrgName)
+	} else if org := os.Getenv("TFC_ORG"); org != "" && !localPlan {
+		*tfcOrgName = org
+		log.Printf("Using Terraform Cloud organization %s from TFC_ORG", org)
+	}
+	if len(*tfcWorkspaceNames) > 0 {
+		log.Printf("Using Terraform Cloud workspace %s from --tfcWorkspace", strings.Join(*tfcWorkspaceNames, ", "))
+	} else if *tfcWorkspaceTag != "" {
+		log.Printf("Using Terraform Cloud workspaces tagged %s from --tfcWorkspaceTag", *tfcWorkspaceTag)
+	} else if workspace := os.Getenv("TFC_WORKSPACE"); workspace != "" && !localPlan {
+		*tfcWorkspaceNames = []string{workspace}
+		log.Printf("Using Terraform Cloud workspace %s from TFC_WORKSPACE", workspace)
+	}
+
+	parsedNotifyTimeout, err := time.ParseDuration(*notifyTimeout)
+	if err != nil {
+		log.Fatalf("Invalid --notifyTimeout: %s", err)
+	}
+
+	parsedTFCPollInterval, err := parsePositiveDuration(*tfcPollInterval)
+	if err != nil {
+		log.Fatalf("Invalid --tfcPollInterval: %s", err)
+	}
+
+	var parsedTFCPlanDelay time.Duration
+	if *tfcPlanDelay != "" {
+		parsedTFCPlanDelay, err = parsePositiveDuration(*tfcPlanDelay)
+		if err != nil {
+			log.Fatalf("Invalid --tfcPlanDelay: %s", err)
+		}
+	}
+
+	parsedOnlyActions, err := parseActions(*onlyActions)
+	if err != nil {
+		log.Fatalf("Invalid --onlyActions: %s", err)
+	}
+
+	parsedPinModules := []string{}
+	for _, module := range strings.Split(*pinModules, ",") {
+		if module = strings.TrimSpace(module); module != "" {
+			parsedPinModules = append(parsedPinModules, module)
+		}
+	}
+
+	parsedTfEnvVars, err := parseTfEnvVars(*tfEnvVars)
+	if err != nil {
+		log.Fatalf("Invalid --tfEnvVar: %s", err)
+	}
+
+	parsedTfPlanOptions, tfPlanCLIArgs, err := parsePlanArgs(*tfPlanArgs)
+	if err != nil {
+		log.Fatalf("Invalid --tfPlanArg: %s", err)
+	}
+	if err := setPlanCLIArgs(tfPlanCLIArgs); err != nil {
+		log.Fatal(err.Error())
+	}
+
+	parsedReadTimeout, err := parsePositiveDuration(*readTimeout)
+	if err != nil {
+		log.Fatalf("Invalid --readTimeout: %s", err)
+	}
+
+	parsedWriteTimeout, err := parsePositiveDuration(*writeTimeout)
+	if err != nil {
+		log.Fatalf("Invalid --writeTimeout: %s", err)
+	}
+
+	parsedIdleTimeout, err := parsePositiveDuration(*idleTimeout)
+	if err != nil {
+		log.Fatalf("Invalid --idleTimeout: %s", err)
+	}
+
+	if *maxUploadBytes <= 0 {
+		log.Fatalf("Invalid --maxUploadBytes: must be positive, got %d", *maxUploadBytes)
+	}
+
+	if *rateLimit < 0 {
+		log.Fatalf("Invalid --rateLimit: must not be negative, got %v", *rateLimit)
+	}
+
+	var parsedTFCSince time.Time
+	if *tfcSince != "" {
+		parsedTFCSince, err = time.Parse(time.RFC3339, *tfcSince)
+		if err != nil {
+			log.Fatalf("Invalid --tfcSince: %s", err)
+		}
+		if *tfcNewRun {
+			log.Fatal("--tfcSince can't be combined with --tfcNewRun")
+		}
+	}
+
+	if *showApplyResult {
+		if len(*tfcWorkspaceNames) == 0 && *tfcWorkspaceTag == "" {
+			log.Fatal("--showApplyResult requires --tfcWorkspace or --tfcWorkspaceTag")
+		}
+		if *tfcNewRun {
+			log.Fatal("--showApplyResult can't be combined with --tfcNewRun, since the new run isn't applied")
+		}
+		if *anonymize {
+			log.Fatal("--showApplyResult can't be combined with --anonymize")
+		}
+	}
+
+	if *showPolicies {
+		if len(*tfcWorkspaceNames) == 0 && *tfcWorkspaceTag == "" {
+			log.Fatal("--showPolicies requires --tfcWorkspace or --tfcWorkspaceTag")
+		}
+		if *anonymize {
+			log.Fatal("--showPolicies can't be combined with --anonymize")
+		}
+	}
+
+	if *planHistory != "" && *anonymize {
+		log.Fatal("--planHistory can't be combined with --anonymize, since each plan would get different pseudonyms")
 	}
 
-	if *getVersion {
-		fmt.Printf("Rover v%s\n", VERSION)
-		return
+	// These flags take original module addresses or show tag values next to the pseudonyms
+	if *anonymize {
+		if *moduleFocus != "" {
+			log.Fatal("--moduleFocus can't be combined with --anonymize")
+		}
+		if *pinModules != "" {
+			log.Fatal("--pinModules can't be combined with --anonymize")
+		}
+		if *groupByTag != "" {
+			log.Fatal("--groupByTag can't be combined with --anonymize")
+		}
 	}
 
-	for _, tfVarFile := range *tfVarsFilesTmp {
-		tfVarsFiles.Set(tfVarFile)
+	// Each workspace becomes a module named after it when merging their plans
+	for flag, names := range map[string][]string{"--workspaceName": *workspaceNames, "--tfcWorkspace": *tfcWorkspaceNames} {
+		seen := map[string]bool{}
+		for _, name := range names {
+			if seen[name] {
+				log.Fatalf("%s %s is given more than once", flag, name)
+			}
+			seen[name] = true
+		}
+	}
+
+	if len(*workspaceNames) > 1 && *keepPlan != "" {
+		if fi, err := os.Stat(*keepPlan); err != nil || !fi.IsDir() {
+			log.Fatal("--keepPlan must be an existing directory to keep the plans of several --workspaceName")
+		}
+	}
+
+	if *apiOnly {
+		if *standalone {
+			log.Fatal("--apiOnly can't be combined with --standalone")
+		}
+		if *genImage {
+			log.Fatal("--apiOnly can't be combined with --genImage, since the image is rendered by the frontend")
+		}
+		if len(*configs) > 0 {
+			log.Fatal("--apiOnly can't be combined with --config")
+		}
 	}
-	for _, tfVar := range *tfVarsTmp {
-		tfVars.Set(tfVar)
+
+	// Without the frontend, Rover can still serve the API and export the graph as data
+	fe, hasFrontend := embeddedFrontend()
+	if !hasFrontend {
+		switch {
+		case *genImage:
+			log.Fatalf("--genImage requires the frontend, which renders the image: %s", FRONTEND_NOT_BUILT)
+		case *standalone && *format == FORMAT_ZIP:
+			log.Fatalf("--standalone requires the frontend for the zip format, use --format %s or %s instead: %s", FORMAT_CYTOSCAPE, FORMAT_CSV, FRONTEND_NOT_BUILT)
+		case len(*configs) > 0:
+			log.Fatalf("--config requires the frontend: %s", FRONTEND_NOT_BUILT)
+		case !*standalone && !*apiOnly:
+			logStatus(COLOR_YELLOW, "WARNING: %s. Serving only the API like --apiOnly", FRONTEND_NOT_BUILT)
+			*apiOnly = true
+		}
 	}
-	for _, tfBackendConfig := range *tfBackendConfigsTmp {
-		tfBackendConfigs.Set(tfBackendConfig)
+
+	if *eventLog != "" && *standalone {
+		log.Fatal("--eventLog can't be combined with --standalone, since no server receives the events")
 	}
 
-	log.Println("Starting Rover...")
+	if *stdoutManifest {
+		if !*standalone && !*genImage {
+			log.Fatal("--stdout requires --standalone or --genImage")
+		}
+		if *treeOutput || *stats {
+			log.Fatal("--stdout can't be combined with --treeOutput or --stats, which print to stdout too")
+		}
+	}
 
-	parsedTfVarsFiles := strings.Split(tfVarsFiles.String(), ",")
-	parsedTfVars := strings.Split(tfVars.String(), ",")
-	parsedTfBackendConfigs := strings.Split(tfBackendConfigs.String(), ",")
+	if *planPrefix == "" || strings.ContainsAny(*planPrefix, `/\`) {
+		log.Fatalf("Invalid --planPrefix %q: must be a file name without path separators", *planPrefix)
+	}
 
 	path, err := os.Getwd()
 	if err != nil {
 		log.Fatal(errors.New("unable to get current working directory"))
 	}
 
+	// Relative plan paths are resolved against the current directory unless --planPathRelativeTo is
+	// workingDir, which is itself relative to the current directory
+	planPathBase := path
+	if *planPathRelativeTo == PLAN_PATH_RELATIVE_TO_WORKING_DIR {
+		planPathBase = *workingDir
+		if !filepath.IsAbs(planPathBase) {
+			planPathBase = filepath.Join(path, planPathBase)
+		}
+	}
+
 	planPath := *planPathPtr
 	if planPath != "" {
 		if !strings.HasPrefix(planPath, "/") {
-			planPath = filepath.Join(path, planPath)
+			planPath = filepath.Join(planPathBase, planPath)
+		}
+	}
+
+	// Terraform runs in the working directory, so the CLI config file is resolved up front
+	if *cliConfigFile != "" {
+		if !filepath.IsAbs(*cliConfigFile) {
+			*cliConfigFile = filepath.Join(path, *cliConfigFile)
+		}
+		if err := checkFile(*cliConfigFile); err != nil {
+			log.Fatalf("Invalid --cliConfigFile: %s", err)
 		}
 	}
 
 	planJSONPath := *planJSONPathPtr
-	if planJSONPath != "" {
+	if planJSONPath != "" && !isObjectURL(planJSONPath) {
 		if !strings.HasPrefix(planJSONPath, "/") {
-			planJSONPath = filepath.Join(path, planJSONPath)
+			planJSONPath = filepath.Join(planPathBase, planJSONPath)
+		}
+	}
+
+	stateJSONPath := *stateJSONPathPtr
+	if stateJSONPath != "" {
+		if !strings.HasPrefix(stateJSONPath, "/") {
+			stateJSONPath = filepath.Join(planPathBase, stateJSONPath)
 		}
 	}
 
 	r := rover{
-		Name:             *name,
-		WorkingDir:       *workingDir,
-		TfPath:           *tfPath,
-		PlanPath:         planPath,
-		PlanJSONPath:     planJSONPath,
-		ShowSensitive:    *showSensitive,
-		GenImage:         *genImage,
-		TfVarsFiles:      parsedTfVarsFiles,
-		TfVars:           parsedTfVars,
-		TfBackendConfigs: parsedTfBackendConfigs,
-		WorkspaceName:    *workspaceName,
-		TFCOrgName:       *tfcOrgName,
-		TFCWorkspaceName: *tfcWorkspaceName,
-		TFCNewRun:        *tfcNewRun,
+		Name:              *name,
+		WorkingDir:        *workingDir,
+		TfPath:            *tfPath,
+		Engine:            Engine(*engine),
+		PlanPath:          planPath,
+		PlanJSONPath:      planJSONPath,
+		StateJSONPath:     stateJSONPath,
+		ShowSensitive:     *showSensitive,
+		GenImage:          *genImage,
+		TfVarsFiles:       *tfVarsFiles,
+		TfVars:            *tfVars,
+		TfVarsOrder:       tfVarsOrder(os.Args, *tfVarsFiles, *tfVars),
+		TfBackendConfigs:  *tfBackendConfigs,
+		WorkspaceNames:    *workspaceNames,
+		TFCOrgName:        *tfcOrgName,
+		TFCWorkspaceNames: *tfcWorkspaceNames,
+		TFCWorkspaceTag:   *tfcWorkspaceTag,
+		TFCNewRun:         *tfcNewRun,
+		ModuleFocus:       *moduleFocus,
+		PinModules:        parsedPinModules,
+		ChangesOnly:       *changesOnly,
+		NotifyURL:         *notifyURL,
+		NotifyTimeout:     parsedNotifyTimeout,
+		NotifyRetries:     *notifyRetries,
+		CriticalPath:      *criticalPath,
+		MaxResources:      *maxResources,
+		OnOverflow:        *onOverflow,
+		ZipFileName:       *zipFileName,
+		VerboseErrors:     *verboseErrors,
+		Anonymize:         *anonymize,
+		AnonymizeMapFile:  *anonymizeMapFile,
+		KeepPlan:          *keepPlan,
+		Profile:           *profile,
+		PlanPrefix:        *planPrefix,
+		Diagnostics:       []Diagnostic{},
+		SanitizeMode:      *sanitizeMode,
+		assetsMu:          &sync.RWMutex{},
+		refreshMu:         &sync.Mutex{},
+		progress:          newProgressHub(),
+		EdgeDirection:     *edgeDirection,
+		TFCPollInterval:   parsedTFCPollInterval,
+		TFCPlanDelay:      parsedTFCPlanDelay,
+		TFCInsecure:       *tfcInsecure,
+		ExpandEnvVars:     *expandEnvVars,
+		TFCNoCancel:       *tfcNoCancel,
+		PlanJSONFormat:    *planJSONFormat,
+		ShowOutputs:       *showOutputs,
+		Layout:            *layout,
+		MaxModuleDepth:    *maxModuleDepth,
+		OnlyActions:       parsedOnlyActions,
+		OnlyResources:     parseAddressPatterns(*onlyResources),
+		ExcludeResources:  parseAddressPatterns(*excludeResources),
+		IgnoreInitErrors:  *ignoreInitErrors,
+		ImageLabels:       *imageLabels,
+		Lock:              *lock == "true",
+		OutputDir:         *outputDir,
+		TFCSince:          parsedTFCSince,
+		ShowApplyResult:   *showApplyResult,
+		ShowPolicies:      *showPolicies,
+		ReadTimeout:       parsedReadTimeout,
+		WriteTimeout:      parsedWriteTimeout,
+		IdleTimeout:       parsedIdleTimeout,
+		MaxUploadBytes:    int64(*maxUploadBytes),
+		AllowRefresh:      *allowRefresh,
+		AllowUpload:       *allowUpload,
+		RateLimit:         *rateLimit,
+		ImageSummary:      *imageSummary,
+		ImageLegend:       *imageLegend,
+		CollapseInstances: *collapseInstances,
+		StaticLayout:      *staticLayout,
+		EventLog:          *eventLog,
+		HighlightUnknown:  *highlightUnknown,
+		OnComplete:        *onComplete,
+		CLIConfigFile:     *cliConfigFile,
+		TfEnvVars:         parsedTfEnvVars,
+		TfPlanOptions:     parsedTfPlanOptions,
+		RelativePaths:     *relativePaths,
+		APIOnly:           *apiOnly,
+		GroupByTag:        *groupByTag,
+		InfracostPath:     *infracostPath,
+		PlanHistory:       *planHistory,
+	}
+
+	if *checkOnly {
+		if !printChecks(os.Stdout, r.checkInputs()) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *configURL != "" {
+		r.FrontendConfig = fetchFrontendConfig(*configURL)
+	}
+
+	if len(*configs) > 0 {
+		if *standalone {
+			log.Fatal("--standalone can't be combined with --config")
+		}
+		r.serveConfigs(*configs, *ipPort)
+		return
 	}
 
 	// Generate assets
+	var stopCPUProfile func()
+	if r.Profile {
+		stopCPUProfile, err = startCPUProfile(CPU_PROFILE_FILE)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
 	err = r.generateAssets()
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 
-	log.Println("Done generating assets.")
+	if r.Profile {
+		stopCPUProfile()
+		if err := writeHeapProfile(HEAP_PROFILE_FILE); err != nil {
+			log.Println(err)
+		}
+	}
 
-	// Save to file (debug)
-	// saveJSONToFile(name, "plan", "output", r.Plan)
-	// saveJSONToFile(name, "rso", "output", r.Plan)
-	// saveJSONToFile(name, "map", "output", r.Map)
-	// saveJSONToFile(name, "graph", "output", r.Graph)
+	logStatus(COLOR_GREEN, "Done generating assets.")
+	r.reportProgress(PROGRESS_DONE)
 
-	// Embed frontend
-	fe, err := fs.Sub(frontend, "ui/dist")
-	if err != nil {
-		log.Fatalln(err)
+	if *debug {
+		if err := r.saveDebugJSON(); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	if *inventory != "" {
+		if err := r.writeInventory(*inventory); err != nil {
+			log.Fatal(err.Error())
+		}
+		r.Manifest.Inventory = artifactPath(*inventory)
+	}
+
+	if r.NotifyURL != "" {
+		if err := r.notify(); err != nil {
+			log.Println(err)
+		}
+	}
+
+	if r.OnComplete != "" {
+		if err := r.runOnComplete(); err != nil {
+			if !*onCompleteIgnoreError {
+				log.Fatal(err.Error())
+			}
+			logStatus(COLOR_YELLOW, "WARNING: %s", err)
+		}
+	}
+
+	if *treeOutput {
+		r.printTree(os.Stdout)
+	}
+
+	if *stats {
+		if err := r.printStats(os.Stdout); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	if *treeOutput || *stats {
+		return
+	}
+
+	// Printed once every file is generated, also when the server is shut down after generating the image
+	if *stdoutManifest {
+		defer func() {
+			if err := r.printManifest(os.Stdout); err != nil {
+				log.Fatal(err.Error())
+			}
+		}()
+	}
+
+	if *standalone && *format == FORMAT_CYTOSCAPE {
+		cytoscapePath, err := r.outputPath(fmt.Sprintf("%s.cytoscape.json", *zipFileName))
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if err := r.writeCytoscapeGraph(cytoscapePath); err != nil {
+			log.Fatalln(err)
+		}
+		r.Manifest.Cytoscape = artifactPath(cytoscapePath)
+		return
+	}
+
+	if *standalone && *format == FORMAT_CSV {
+		nodesPath, err := r.outputPath(CSV_NODES_FILE)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		edgesPath, err := r.outputPath(CSV_EDGES_FILE)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if err := r.writeGraphCSV(nodesPath, edgesPath); err != nil {
+			log.Fatalln(err)
+		}
+		r.Manifest.CSVNodes = artifactPath(nodesPath)
+		r.Manifest.CSVEdges = artifactPath(edgesPath)
+		return
 	}
-	frontendFS := http.FileServer(http.FS(fe))
 
 	if *standalone {
-		err = r.generateZip(fe, fmt.Sprintf("%s.zip", *zipFileName))
+		zipPath, err := r.outputPath(fmt.Sprintf("%s.zip", *zipFileName))
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		err = r.generateZip(fe, zipPath)
 		if err != nil {
 			log.Fatalln(err)
 		}
 
-		log.Printf("Generated zip file: %s.zip\n", *zipFileName)
+		log.Printf("Generated zip file: %s\n", zipPath)
+		r.Manifest.Zip = artifactPath(zipPath)
 		return
 	}
 
-	err = r.startServer(*ipPort, frontendFS)
+	err = r.startServer(*ipPort, fe)
 	if err != nil {
 		// http.Serve() returns error on shutdown
 		if *genImage {
@@ -267,11 +1158,32 @@ func main() {
 
 }
 
-func (r *rover) generateAssets() error {
+func (r *rover) generateAssets() (err error) {
+	start := time.Now()
+	defer func() {
+		r.recordGeneration(time.Since(start), err)
+		if err != nil {
+			r.reportProgressError(err)
+		}
+	}()
+
 	// Get Plan
-	err := r.getPlan()
+	err = r.getPlan()
 	if err != nil {
-		return fmt.Errorf("unable to parse Plan: %s", err)
+		return fmt.Errorf("unable to parse Plan: %w", err)
+	}
+	r.reportProgress(PROGRESS_PLAN)
+
+	r.truncateModuleDepth()
+
+	// Remove filtered resources before generating anything from the plan
+	r.FilterPlan()
+
+	if r.Anonymize {
+		err = r.AnonymizePlan()
+		if err != nil {
+			return err
+		}
 	}
 
 	// Generate RSO, Map, Graph
@@ -279,16 +1191,40 @@ func (r *rover) generateAssets() error {
 	if err != nil {
 		return err
 	}
+	r.reportProgress(PROGRESS_RSO)
+
+	if r.RelativePaths {
+		r.relativizePaths()
+	}
+
+	if r.InfracostPath != "" {
+		cost, err := r.estimateCost()
+		if err != nil {
+			logStatus(COLOR_YELLOW, "WARNING: unable to estimate costs: %s", err)
+		}
+		r.RSO.Cost = cost
+	}
 
 	err = r.GenerateMap()
 	if err != nil {
 		return err
 	}
+	r.reportProgress(PROGRESS_MAP)
 
 	err = r.GenerateGraph()
 	if err != nil {
 		return err
 	}
+	r.reportProgress(PROGRESS_GRAPH)
+
+	if r.PlanHistory != "" {
+		err = r.generateHistory()
+		if err != nil {
+			return err
+		}
+	}
+
+	r.GeneratedAt = time.Now()
 
 	return nil
 }
@@ -300,17 +1236,16 @@ func (r *rover) getPlan() error {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	tf, err := tfexec.NewTerraform(r.WorkingDir, r.TfPath)
-	if err != nil {
-		return err
-	}
+	// Recorded while reading the plans, in a new map so the assets being served aren't modified
+	r.PreviousAddresses = map[string]string{}
+	r.ReplaceReasons = map[string]string{}
 
 	planSanitizer := func(r *rover) {
 		if r.ShowSensitive || r.Plan == nil {
 			return
 		}
 
-		tmp, err := sanitize.SanitizePlan(r.Plan)
+		tmp, err := r.sanitizePlan(r.Plan)
 		if err != nil {
 			log.Println("Failed to sanitize plan file!")
 			return
@@ -321,199 +1256,395 @@ func (r *rover) getPlan() error {
 	}
 	defer planSanitizer(r)
 
+	// Uploaded plans replace whichever plan source Rover was started with
+	if r.uploadedPlan != nil {
+		r.Plan, err = r.parseJSONPlan("upload", "", r.uploadedPlan)
+		return err
+	}
+
 	// If user provided path to plan file
 	if r.PlanPath != "" {
 		log.Println("Using provided plan...")
-		r.Plan, err = tf.ShowPlanFile(context.Background(), r.PlanPath)
+		tf, err := r.newTerraform()
+		if err != nil {
+			return err
+		}
+		if err := r.checkVersion(tf); err != nil {
+			return err
+		}
+		r.Plan, err = r.showPlanFile(tf, r.PlanPath, "")
 		if err != nil {
-			return fmt.Errorf("unable to read Plan (%s): %s", r.PlanPath, err)
+			return withKind(ErrPlanParse, fmt.Errorf("unable to read Plan (%s): %w", r.PlanPath, err))
 		}
 		return nil
 	}
 
-	// If user provided path to plan JSON file
+	// Plan JSON, state JSON and Terraform Cloud plans don't need the Terraform binary or an initialized working directory
+	if r.StateJSONPath != "" {
+		return r.getStatePlan()
+	}
+
 	if r.PlanJSONPath != "" {
-		log.Println("Using provided JSON plan...")
+		return r.getJSONPlans()
+	}
 
-		planJsonFile, err := os.Open(r.PlanJSONPath)
-		if err != nil {
-			return fmt.Errorf("unable to read Plan (%s): %s", r.PlanJSONPath, err)
-		}
-		defer planJsonFile.Close()
+	// If user specified TFC workspace
+	if r.usesTFC() {
+		return r.getTFCPlans()
+	}
 
-		planJson, err := io.ReadAll(planJsonFile)
-		if err != nil {
-			return fmt.Errorf("unable to read Plan (%s): %s", r.PlanJSONPath, err)
-		}
+	tf, err := r.newTerraform()
+	if err != nil {
+		return err
+	}
+
+	// Capture stderr to summarize failures
+	var stderr bytes.Buffer
+	tf.SetStderr(&stderr)
+
+	if err := r.checkVersion(tf); err != nil {
+		return err
+	}
+
+	logStatus(COLOR_CYAN, "Initializing %s...", r.Engine.Name())
+
+	// Create TF Init options
+	var tfInitOptions []tfexec.InitOption
+	tfInitOptions = append(tfInitOptions, tfexec.Upgrade(true))
+
+	// Add *.tfbackend files and key=value backend configs
+	backendConfigOptions, err := r.backendConfigOptions()
+	if err != nil {
+		return err
+	}
+	tfInitOptions = append(tfInitOptions, backendConfigOptions...)
+
+	// tfInitOptions = append(tfInitOptions, tfexec.LockTimeout("60s"))
 
-		if err := json.Unmarshal(planJson, &r.Plan); err != nil {
-			return fmt.Errorf("unable to read Plan (%s): %s", r.PlanJSONPath, err)
+	err = tf.Init(context.Background(), tfInitOptions...)
+	if err != nil {
+		if !r.IgnoreInitErrors || !initialized(r.WorkingDir) {
+			return withKind(ErrInitFailed, fmt.Errorf("unable to initialize %s Plan: %s", r.Engine.Name(), r.compactError(err, &stderr)))
 		}
+		logStatus(COLOR_YELLOW, "WARNING: unable to initialize %s, planning with the providers and modules installed by a previous init: %s", r.Engine.Name(), r.compactError(err, &stderr))
+	}
+	r.reportProgress(PROGRESS_INIT)
 
-		return nil
+	// Terraform has no option to skip these, so at least make them visible
+	if autoVarsFiles := autoVarsFiles(r.WorkingDir); len(autoVarsFiles) > 0 {
+		log.Printf("Terraform automatically loads variables from %s", strings.Join(autoVarsFiles, ", "))
 	}
 
-	// If user specified TFC workspace
-	if r.TFCWorkspaceName != "" {
-		tfcToken := os.Getenv("TFC_TOKEN")
+	// Without --workspaceName, plan in the currently selected workspace
+	workspaceNames := r.WorkspaceNames
+	if len(workspaceNames) == 0 {
+		workspaceNames = []string{""}
+	}
 
-		if tfcToken == "" {
-			return errors.New("TFC_TOKEN environment variable not set")
+	// Switch back to the selected workspace once the plans of several workspaces are generated
+	if len(workspaceNames) > 1 {
+		selected, err := tf.WorkspaceShow(context.Background())
+		if err != nil {
+			return fmt.Errorf("unable to show selected workspace: %s", err)
 		}
+		defer func() {
+			if err := tf.WorkspaceSelect(context.Background(), selected); err != nil {
+				log.Printf("Unable to select %s workspace again: %s", selected, err)
+			}
+		}()
+	}
 
-		if r.TFCOrgName == "" {
-			return errors.New("must specify Terraform Cloud organization to retrieve plan from Terraform Cloud")
+	plans := []*tfjson.Plan{}
+	diagnostics := []Diagnostic{}
+	for _, workspaceName := range workspaceNames {
+		if workspaceName != "" {
+			log.Printf("Running in %s workspace...", workspaceName)
+			err = tf.WorkspaceSelect(context.Background(), workspaceName)
+			if err != nil {
+				return fmt.Errorf("unable to select workspace (%s): %s", workspaceName, err)
+			}
 		}
 
-		config := &tfe.Config{
-			Token: tfcToken,
+		// Merged plans prefix addresses with a module named after the workspace
+		prefix := ""
+		if len(workspaceNames) > 1 {
+			prefix = fmt.Sprintf("module.%s", workspaceName)
 		}
 
-		client, err := tfe.NewClient(config)
+		plan, err := r.runPlan(tf, &stderr, tmpDir, workspaceName, prefix)
 		if err != nil {
-			return fmt.Errorf("unable to connect to Terraform Cloud. %s", err)
+			return err
 		}
+		plans = append(plans, plan)
+		diagnostics = append(diagnostics, r.Diagnostics...)
+	}
+	r.Diagnostics = diagnostics
+
+	if len(plans) == 1 {
+		r.Plan = plans[0]
+		return nil
+	}
+
+	log.Printf("Merging plans from %d workspaces...", len(plans))
+	plan, err := mergePlans(workspaceNames, plans)
+	if err != nil {
+		return err
+	}
+	r.Plan = plan
+
+	return nil
+}
+
+// runPlan plans the working directory in the selected workspace and returns the plan.
+// The plan file is named after the workspace, so plans kept from several workspaces don't collide.
+// The previous addresses of moved resources are recorded with prefix, the module prefix of the
+// plan's addresses once merged.
+func (r *rover) runPlan(tf *tfexec.Terraform, stderr *bytes.Buffer, tmpDir string, workspaceName string, prefix string) (*tfjson.Plan, error) {
+	var err error
+
+	logStatus(COLOR_CYAN, "Generating plan...")
+	planPath := fmt.Sprintf("%s/%s-%v", tmpDir, r.PlanPrefix, time.Now().Unix())
+	if workspaceName != "" {
+		planPath = fmt.Sprintf("%s/%s-%s-%v", tmpDir, r.PlanPrefix, workspaceName, time.Now().Unix())
+	}
+
+	// Create TF Plan options
+	var tfPlanOptions []tfexec.PlanOption
+	tfPlanOptions = append(tfPlanOptions, tfexec.Out(planPath))
+
+	if !r.Lock {
+		logStatus(COLOR_YELLOW, "WARNING: planning without locking the state, the plan may be based on stale state if it is changed concurrently")
+		tfPlanOptions = append(tfPlanOptions, tfexec.Lock(false))
+	}
+
+	// Add *.tfvars files and Terraform variables
+	varOptions, err := r.varOptions(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	tfPlanOptions = append(tfPlanOptions, varOptions...)
+	tfPlanOptions = append(tfPlanOptions, r.TfPlanOptions...)
+
+	stderr.Reset()
+	if r.supportsPlanJSON() {
+		// Stream the plan output as JSON to capture its diagnostics
+		var planOutput bytes.Buffer
+		_, err = tf.PlanJSON(context.Background(), &planOutput, tfPlanOptions...)
+		r.Diagnostics = parseDiagnostics(planOutput.Bytes())
 
-		// Get TFC Workspace
-		ws, err := client.Workspaces.Read(context.Background(), r.TFCOrgName, r.TFCWorkspaceName)
 		if err != nil {
-			return fmt.Errorf("unable to list workspace %s in %s organization. %s", r.TFCWorkspaceName, r.TFCOrgName, err)
+			if e := diagnosticsError(r.Diagnostics); e != "" && !r.VerboseErrors {
+				return nil, withKind(ErrPlanFailed, fmt.Errorf("unable to run Plan: %s", e))
+			}
+			return nil, withKind(ErrPlanFailed, fmt.Errorf("unable to run Plan: %s", r.compactError(err, stderr)))
 		}
 
-		// Retrieve all runs from specified TFC workspace
-		runs, err := client.Runs.List(context.Background(), ws.ID, &tfe.RunListOptions{})
+		if len(r.Diagnostics) > 0 {
+			log.Printf("Plan produced %d diagnostics", len(r.Diagnostics))
+		}
+	} else {
+		_, err = tf.Plan(context.Background(), tfPlanOptions...)
 		if err != nil {
-			return fmt.Errorf("unable to retrieve plan from %s in %s organization. %s", r.TFCWorkspaceName, r.TFCOrgName, err)
+			return nil, withKind(ErrPlanFailed, fmt.Errorf("unable to run Plan: %s", r.compactError(err, stderr)))
+		}
+	}
+
+	plan, err := r.showPlanFile(tf, planPath, prefix)
+	if err != nil {
+		return nil, withKind(ErrPlanParse, fmt.Errorf("unable to read Plan: %w", err))
+	}
+
+	// Save plan file before the temporary directory is removed
+	if r.KeepPlan != "" {
+		keepPath := r.KeepPlan
+		if fi, err := os.Stat(keepPath); err == nil && fi.IsDir() {
+			keepPath = filepath.Join(keepPath, filepath.Base(planPath))
 		}
 
-		run := runs.Items[0]
+		if err := copyFile(planPath, keepPath); err != nil {
+			return nil, fmt.Errorf("unable to keep Plan (%s): %s", r.KeepPlan, err)
+		}
+		log.Printf("Saved plan file to %s", keepPath)
+	}
 
-		// Get most recent plan item
-		planID := runs.Items[0].Plan.ID
+	return plan, nil
+}
 
-		// Run hasn't been applied or discarded, therefore is still "actionable" by user
-		runIsActionable := run.StatusTimestamps.AppliedAt.IsZero() && run.StatusTimestamps.DiscardedAt.IsZero()
+// newTerraform returns the Terraform binary to run in the working directory, with the
+// environment set up for it
+func (r *rover) newTerraform() (*tfexec.Terraform, error) {
+	if err := r.setTerraformEnv(); err != nil {
+		return nil, err
+	}
 
-		if runIsActionable && r.TFCNewRun {
-			return fmt.Errorf("did not create new run. %s in %s in %s is still active", run.ID, r.TFCWorkspaceName, r.TFCOrgName)
+	if r.TfPath == "" {
+		tfPath, err := r.Engine.FindPath()
+		if err != nil {
+			return nil, err
 		}
+		r.TfPath = tfPath
+		log.Printf("Using %s binary %s", r.Engine.Name(), r.TfPath)
+	}
 
-		// If latest run is not actionable, rover will create new run
-		if r.TFCNewRun {
-			// Create new run in specified TFC workspace
-			newRun, err := client.Runs.Create(context.Background(), tfe.RunCreateOptions{
-				Refresh:   &TRUE,
-				Workspace: ws,
-			})
-			if err != nil {
-				return fmt.Errorf("unable to generate new run from %s in %s organization. %s", r.TFCWorkspaceName, r.TFCOrgName, err)
-			}
+	return tfexec.NewTerraform(r.WorkingDir, r.TfPath)
+}
 
-			run = newRun
+// showPlanFile reads the plan file as JSON, recording the previous addresses of moved resources
+// with prefix from the JSON output since terraform-json doesn't decode them
+func (r *rover) showPlanFile(tf *tfexec.Terraform, planPath string, prefix string) (*tfjson.Plan, error) {
+	var planJson bytes.Buffer
+	tf.SetStdout(&planJson)
+	defer tf.SetStdout(io.Discard)
 
-			log.Printf("Starting new Terraform Cloud run in %s workspace...", r.TFCWorkspaceName)
+	plan, err := tf.ShowPlanFile(context.Background(), planPath)
+	if err != nil {
+		return nil, err
+	}
+	r.readPreviousAddresses(prefix, planJson.Bytes())
+	r.readReplaceReasons(prefix, planJson.Bytes())
 
-			// Wait maximum of 5 mins
-			for i := 0; i < 30; i++ {
-				run, err := client.Runs.Read(context.Background(), newRun.ID)
-				if err != nil {
-					return fmt.Errorf("unable to retrieve run from %s in %s organization. %s", r.TFCWorkspaceName, r.TFCOrgName, err)
-				}
+	return plan, nil
+}
 
-				if run.Plan != nil {
-					planID = run.Plan.ID
-					// Add 20 second timeout so plan JSON becomes available
-					time.Sleep(20 * time.Second)
-					log.Printf("Run %s to completed!", newRun.ID)
-					break
-				}
+// outputPath returns the path of the named output file in --outputDir, creating the directory if missing
+func (r *rover) outputPath(name string) (string, error) {
+	if r.OutputDir == "" {
+		return name, nil
+	}
 
-				time.Sleep(10 * time.Second)
-				log.Printf("Waiting for run %s to complete (%ds)...", newRun.ID, 10*(i+1))
-			}
+	if err := os.MkdirAll(r.OutputDir, 0755); err != nil {
+		return "", fmt.Errorf("unable to create output directory (%s): %s", r.OutputDir, err)
+	}
 
-			if planID == "" {
-				return fmt.Errorf("timeout waiting for plan to complete in %s in %s organization. %s", r.TFCWorkspaceName, r.TFCOrgName, err)
-			}
-		}
+	return filepath.Join(r.OutputDir, name), nil
+}
 
-		// Get most recent plan file
-		planBytes, err := client.Plans.ReadJSONOutput(context.Background(), planID)
-		if err != nil {
-			return fmt.Errorf("unable to retrieve plan from %s in %s organization. %s", r.TFCWorkspaceName, r.TFCOrgName, err)
-		}
-		// If empty plan file
-		if string(planBytes) == "" {
-			return fmt.Errorf("empty plan, check run %s in %s in %s is not pending", run.ID, r.TFCWorkspaceName, r.TFCOrgName)
+// setTerraformEnv sets TF_CLI_CONFIG_FILE and the --tfEnvVar variables in Rover's environment,
+// which Terraform inherits. tfexec refuses TF_VAR_ variables set with tf.SetEnv, and replacing
+// the environment with it would drop the TF_VAR_ variables set by the user.
+func (r *rover) setTerraformEnv() error {
+	if r.CLIConfigFile != "" {
+		if err := os.Setenv("TF_CLI_CONFIG_FILE", r.CLIConfigFile); err != nil {
+			return fmt.Errorf("unable to set TF_CLI_CONFIG_FILE: %s", err)
 		}
+	}
 
-		if err := json.Unmarshal(planBytes, &r.Plan); err != nil {
-			return fmt.Errorf("unable to parse plan (ID: %s) from %s in %s organization.: %s", planID, r.TFCWorkspaceName, r.TFCOrgName, err)
+	for name, value := range r.TfEnvVars {
+		if err := os.Setenv(TF_VAR_ENV_PREFIX+name, value); err != nil {
+			return fmt.Errorf("unable to set %s%s: %s", TF_VAR_ENV_PREFIX, name, err)
 		}
-
-		return nil
 	}
 
-	log.Println("Initializing Terraform...")
-
-	// Create TF Init options
-	var tfInitOptions []tfexec.InitOption
-	tfInitOptions = append(tfInitOptions, tfexec.Upgrade(true))
+	return nil
+}
 
-	// Add *.tfbackend files
-	for _, tfBackendConfig := range r.TfBackendConfigs {
-		if tfBackendConfig != "" {
-			tfInitOptions = append(tfInitOptions, tfexec.BackendConfig(tfBackendConfig))
+// parseTfEnvVars parses key=value variables, the key optionally prefixed with TF_VAR_
+func parseTfEnvVars(entries []string) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimPrefix(name, TF_VAR_ENV_PREFIX)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q must be key=value", entry)
 		}
+		vars[name] = value
 	}
 
-	// tfInitOptions = append(tfInitOptions, tfexec.LockTimeout("60s"))
+	return vars, nil
+}
 
-	err = tf.Init(context.Background(), tfInitOptions...)
-	if err != nil {
-		return fmt.Errorf("unable to initialize Terraform Plan: %s", err)
+// initialized returns true if dir was initialized before, so it has providers and modules installed
+func initialized(dir string) bool {
+	dataDir := os.Getenv("TF_DATA_DIR")
+	if dataDir == "" {
+		dataDir = ".terraform"
+	}
+	if !filepath.IsAbs(dataDir) {
+		dataDir = filepath.Join(dir, dataDir)
 	}
 
-	if r.WorkspaceName != "" {
-		log.Printf("Running in %s workspace...", r.WorkspaceName)
-		err = tf.WorkspaceSelect(context.Background(), r.WorkspaceName)
-		if err != nil {
-			return fmt.Errorf("unable to select workspace (%s): %s", r.WorkspaceName, err)
+	info, err := os.Stat(dataDir)
+	return err == nil && info.IsDir()
+}
+
+// backendConfigOptions converts --tfBackendConfig entries to init options.
+// Entries containing "=" are inline key=value pairs, everything else is a path to a *.tfbackend file.
+func (r *rover) backendConfigOptions() ([]tfexec.InitOption, error) {
+	var options []tfexec.InitOption
+
+	for _, tfBackendConfig := range r.TfBackendConfigs {
+		if tfBackendConfig == "" {
+			continue
+		}
+
+		if key, _, inline := strings.Cut(tfBackendConfig, "="); inline {
+			if strings.TrimSpace(key) == "" {
+				return nil, fmt.Errorf("invalid backend config (%s): missing key", tfBackendConfig)
+			}
+		} else {
+			// Terraform resolves backend config files relative to the working directory
+			path := tfBackendConfig
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(r.WorkingDir, path)
+			}
+			if _, err := os.Stat(path); err != nil {
+				return nil, fmt.Errorf("unable to read backend config file (%s): %s", tfBackendConfig, err)
+			}
 		}
+
+		options = append(options, tfexec.BackendConfig(tfBackendConfig))
 	}
 
-	log.Println("Generating plan...")
-	planPath := fmt.Sprintf("%s/%s-%v", tmpDir, "roverplan", time.Now().Unix())
+	return options, nil
+}
 
-	// Create TF Plan options
-	var tfPlanOptions []tfexec.PlanOption
-	tfPlanOptions = append(tfPlanOptions, tfexec.Out(planPath))
+// autoVarsFiles returns the tfvars files in dir which Terraform loads automatically, in loading order
+func autoVarsFiles(dir string) []string {
+	files := []string{}
 
-	// Add *.tfvars files
-	for _, tfVarsFile := range r.TfVarsFiles {
-		if tfVarsFile != "" {
-			tfPlanOptions = append(tfPlanOptions, tfexec.VarFile(tfVarsFile))
+	for _, name := range []string{"terraform.tfvars", "terraform.tfvars.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			files = append(files, name)
 		}
 	}
 
-	// Add Terraform variables
-	for _, tfVar := range r.TfVars {
-		if tfVar != "" {
-			tfPlanOptions = append(tfPlanOptions, tfexec.Var(tfVar))
+	// *.auto.tfvars and *.auto.tfvars.json files are loaded in lexical order of their names
+	autoFiles := []string{}
+	for _, pattern := range []string{"*.auto.tfvars", "*.auto.tfvars.json"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, match := range matches {
+			autoFiles = append(autoFiles, filepath.Base(match))
 		}
 	}
+	sort.Strings(autoFiles)
+
+	return append(files, autoFiles...)
+}
+
+// expandVarsFile writes a copy of a tfvars file to dir with environment variable references expanded,
+// and returns the path of the copy
+func (r *rover) expandVarsFile(dir string, i int, tfVarsFile string) (string, error) {
+	// Terraform resolves tfvars files relative to the working directory
+	path := tfVarsFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.WorkingDir, path)
+	}
 
-	_, err = tf.Plan(context.Background(), tfPlanOptions...)
+	content, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("unable to run Plan: %s", err)
+		return "", fmt.Errorf("unable to read tfvars file (%s): %s", tfVarsFile, err)
 	}
 
-	r.Plan, err = tf.ShowPlanFile(context.Background(), planPath)
+	// Keep the file name, Terraform parses *.tfvars.json files as JSON
+	expandedPath, err := filepath.Abs(filepath.Join(dir, fmt.Sprintf("%d-%s", i, filepath.Base(path))))
 	if err != nil {
-		return fmt.Errorf("unable to read Plan: %s", err)
+		return "", err
+	}
+	if err := os.WriteFile(expandedPath, []byte(os.ExpandEnv(string(content))), 0600); err != nil {
+		return "", fmt.Errorf("unable to write expanded tfvars file (%s): %s", tfVarsFile, err)
 	}
 
-	return nil
+	return expandedPath, nil
 }
 
 func enableCors(w *http.ResponseWriter) {
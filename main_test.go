@@ -0,0 +1,175 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+// testRover returns a rover generating its assets from a plan JSON fixture in testdata,
+// where the configuration of the fixtures lives
+func testRover(t *testing.T, fixture string) *rover {
+	t.Helper()
+
+	return &rover{
+		Name:         t.Name(),
+		WorkingDir:   "testdata",
+		TfPath:       EngineTerraform.DefaultPath(),
+		PlanJSONPath: filepath.Join("testdata", fixture),
+		Engine:       EngineTerraform,
+		assetsMu:     &sync.RWMutex{},
+		refreshMu:    &sync.Mutex{},
+	}
+}
+
+func TestParseTfEnvVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "plain value",
+			entries: []string{"region=us-east-1"},
+			want:    map[string]string{"region": "us-east-1"},
+		},
+		{
+			name:    "commas",
+			entries: []string{"zones=a,b,c"},
+			want:    map[string]string{"zones": "a,b,c"},
+		},
+		{
+			name:    "spaces",
+			entries: []string{"description= hello world "},
+			want:    map[string]string{"description": " hello world "},
+		},
+		{
+			name:    "equals signs",
+			entries: []string{"query=a=b==c"},
+			want:    map[string]string{"query": "a=b==c"},
+		},
+		{
+			name:    "list and map values",
+			entries: []string{`tags={"Name" = "web", "Env" = "prod"}`, `ids=["a", "b"]`},
+			want:    map[string]string{"tags": `{"Name" = "web", "Env" = "prod"}`, "ids": `["a", "b"]`},
+		},
+		{
+			name:    "empty value",
+			entries: []string{"suffix="},
+			want:    map[string]string{"suffix": ""},
+		},
+		{
+			name:    "TF_VAR_ prefix",
+			entries: []string{"TF_VAR_region=eu-west-1"},
+			want:    map[string]string{"region": "eu-west-1"},
+		},
+		{
+			name:    "later entries win",
+			entries: []string{"region=us-east-1", "region=eu-west-1"},
+			want:    map[string]string{"region": "eu-west-1"},
+		},
+		{
+			name:    "missing equals sign",
+			entries: []string{"region"},
+			wantErr: true,
+		},
+		{
+			name:    "missing key",
+			entries: []string{"=value"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTfEnvVars(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTfEnvVars() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTfEnvVars() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendConfigOptions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "test.tfbackend"), []byte("bucket = \"state\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	absPath := filepath.Join(dir, "test.tfbackend")
+	if err := os.WriteFile(filepath.Join(dir, "my backend, prod.tfbackend"), []byte("bucket = \"state\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		configs []string
+		want    []tfexec.InitOption
+		wantErr bool
+	}{
+		{
+			name:    "file relative to the working directory",
+			configs: []string{"test.tfbackend"},
+			want:    []tfexec.InitOption{tfexec.BackendConfig("test.tfbackend")},
+		},
+		{
+			name:    "absolute file",
+			configs: []string{absPath},
+			want:    []tfexec.InitOption{tfexec.BackendConfig(absPath)},
+		},
+		{
+			name:    "inline key=value",
+			configs: []string{"bucket=state", "key=path/to=state.tfstate"},
+			want:    []tfexec.InitOption{tfexec.BackendConfig("bucket=state"), tfexec.BackendConfig("key=path/to=state.tfstate")},
+		},
+		{
+			name:    "commas, spaces and equals signs are kept",
+			configs: []string{"key=env/prod,eu/state=1.tfstate", "prefix = a b", "my backend, prod.tfbackend"},
+			want: []tfexec.InitOption{
+				tfexec.BackendConfig("key=env/prod,eu/state=1.tfstate"),
+				tfexec.BackendConfig("prefix = a b"),
+				tfexec.BackendConfig("my backend, prod.tfbackend"),
+			},
+		},
+		{
+			name:    "mixed files and inline settings keep their order",
+			configs: []string{"region=us-east-1", "test.tfbackend", "", "key=state.tfstate"},
+			want: []tfexec.InitOption{
+				tfexec.BackendConfig("region=us-east-1"),
+				tfexec.BackendConfig("test.tfbackend"),
+				tfexec.BackendConfig("key=state.tfstate"),
+			},
+		},
+		{
+			name:    "missing file",
+			configs: []string{"missing.tfbackend"},
+			wantErr: true,
+		},
+		{
+			name:    "inline setting without key",
+			configs: []string{"=state"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rover{WorkingDir: dir, TfBackendConfigs: tt.configs}
+
+			got, err := r.backendConfigOptions()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("backendConfigOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("backendConfigOptions() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
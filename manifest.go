@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// Manifest lists the files Rover generated, printed as JSON to stdout with --stdout so
+// pipelines can pick them up as artifacts
+type Manifest struct {
+	Zip       string `json:"zip,omitempty"`
+	Image     string `json:"image,omitempty"`
+	Cytoscape string `json:"cytoscape,omitempty"`
+	CSVNodes  string `json:"csv_nodes,omitempty"`
+	CSVEdges  string `json:"csv_edges,omitempty"`
+	Inventory string `json:"inventory,omitempty"`
+}
+
+// artifactPath returns the absolute path of a generated file, falling back to the path itself
+func artifactPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
+
+// printManifest writes the manifest of the generated files as JSON to w
+func (r *rover) printManifest(w io.Writer) error {
+	b, err := json.Marshal(r.Manifest)
+	if err != nil {
+		return fmt.Errorf("error producing manifest JSON: %s", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
@@ -22,6 +22,8 @@ const (
 	ResourceTypeResource ResourceType = "resource"
 	ResourceTypeData     ResourceType = "data"
 	ResourceTypeModule   ResourceType = "module"
+	ResourceTypeBoundary ResourceType = "boundary"
+	ResourceTypeGroup    ResourceType = "group"
 	DefaultFileName      string       = "unknown file"
 )
 
@@ -64,6 +66,15 @@ type Resource struct {
 
 	// Resource
 	ChangeAction Action `json:"change_action,omitempty"`
+	// Importing is set if the plan imports the resource, in addition to its change action
+	Importing bool `json:"importing,omitempty"`
+	// MovedFrom is the previous address of a resource moved without other changes
+	MovedFrom string `json:"moved_from,omitempty"`
+
+	// Module
+	// Collapsed is set on the modules requested collapsed with the collapse query parameter of /api/map,
+	// and on the modules outside of --pinModules
+	Collapsed bool `json:"collapsed,omitempty"`
 	// Variable and Output
 	Required  *bool `json:"required,omitempty"`
 	Sensitive bool  `json:"sensitive,omitempty"`
@@ -98,9 +109,11 @@ func (r *rover) GenerateModuleMap(parent *Resource, parentModule string) {
 
 	parentConfig := matchBrackets.ReplaceAllString(parentModule, "")
 	parentConfigured := configs[parentConfig] != nil && configs[parentConfig].Module != nil
+	// Modules above the focused module only lead the way to it
+	parentInFocus := r.inModuleFocus(parentModule)
 
 	// Add variables and outputs with line numbers and file names if configured
-	if parentConfigured && !states[parentModule].IsParent {
+	if parentInFocus && parentConfigured && !states[parentModule].IsParent {
 		for oName, o := range configs[parentConfig].Module.Outputs {
 			fname := filepath.Base(o.Pos.Filename)
 			oid := fmt.Sprintf("%soutput.%s", prefix, oName)
@@ -131,7 +144,7 @@ func (r *rover) GenerateModuleMap(parent *Resource, parentModule string) {
 
 		}
 		// Add variables and Outputs if no configuration files
-	} else if configs[parentConfig] != nil && configs[parentConfig].ModuleConfig.Module != nil && !states[parentModule].IsParent {
+	} else if parentInFocus && configs[parentConfig] != nil && configs[parentConfig].ModuleConfig.Module != nil && !states[parentModule].IsParent {
 		for oName, o := range configs[parentConfig].ModuleConfig.Module.Outputs {
 			oid := fmt.Sprintf("%soutput.%s", prefix, oName)
 			out := &Resource{
@@ -172,6 +185,8 @@ func (r *rover) GenerateModuleMap(parent *Resource, parentModule string) {
 				re.ChangeAction = ActionReplace
 			}
 		}
+		re.Importing = states[id].Change.Importing != nil
+		re.MovedFrom = states[id].MovedFrom
 
 		if rs.Type == ResourceTypeResource || rs.Type == ResourceTypeData {
 			re.ResourceType = configs[configId].ResourceConfig.Type
@@ -200,6 +215,8 @@ func (r *rover) GenerateModuleMap(parent *Resource, parentModule string) {
 						tcr.ChangeAction = ActionReplace
 					}
 				}
+				tcr.Importing = cr.Change.Importing != nil
+				tcr.MovedFrom = cr.MovedFrom
 
 				re.Children[crName] = tcr
 			}
@@ -263,7 +280,7 @@ func (r *rover) GenerateModuleMap(parent *Resource, parentModule string) {
 		}
 
 		// Add locals
-		if configs[configId] != nil && !(re.Type == ResourceTypeModule && childIndex.MatchString(id)) {
+		if parentInFocus && configs[configId] != nil && !(re.Type == ResourceTypeModule && childIndex.MatchString(id)) {
 			expressions := map[string]*tfjson.Expression{}
 
 			if re.Type == ResourceTypeResource {
@@ -346,7 +363,71 @@ func (r *rover) GenerateMap() error {
 		r.GenerateModuleMap(rootModule.Children[DefaultFileName], "")
 	}
 
+	if len(r.PinModules) > 0 {
+		found := map[string]bool{}
+		r.pinModules(mapObj.Root, found)
+		for _, module := range r.PinModules {
+			if !found[module] {
+				logStatus(COLOR_YELLOW, "WARNING: pinned module %s not found in map", module)
+			}
+		}
+	}
+
 	r.Map = mapObj
 
 	return nil
 }
+
+// pinModules collapses the modules that aren't pinned, recording the pinned modules found
+func (r *rover) pinModules(resources map[string]*Resource, found map[string]bool) {
+	for id, re := range resources {
+		if re.Type == ResourceTypeModule {
+			if !r.isPinned(id) {
+				re.Collapsed = true
+			}
+			found[id] = true
+			found[instanceKeyPattern.ReplaceAllString(id, "")] = true
+		}
+		r.pinModules(re.Children, found)
+	}
+}
+
+// collapseModules returns a copy of the map with the modules at the given addresses marked collapsed,
+// leaving the map itself untouched since it is shared by all requests
+func (m *Map) collapseModules(modules []string) (*Map, error) {
+	collapse := map[string]bool{}
+	for _, module := range modules {
+		if module = strings.TrimSpace(module); module != "" {
+			collapse[module] = true
+		}
+	}
+
+	collapsed := *m
+	collapsed.Root = collapseResources(m.Root, collapse)
+
+	for module := range collapse {
+		return nil, fmt.Errorf("%s not found in map", module)
+	}
+
+	return &collapsed, nil
+}
+
+// collapseResources copies resources, marking the modules in collapse and removing them from it once found
+func collapseResources(resources map[string]*Resource, collapse map[string]bool) map[string]*Resource {
+	if resources == nil {
+		return nil
+	}
+
+	copied := make(map[string]*Resource, len(resources))
+	for id, re := range resources {
+		c := *re
+		if re.Type == ResourceTypeModule && collapse[id] {
+			c.Collapsed = true
+			delete(collapse, id)
+		}
+		c.Children = collapseResources(re.Children, collapse)
+		copied[id] = &c
+	}
+
+	return copied
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// mergePlans combines several plans into one. Each plan becomes a module of the root module,
+// named after the matching entry in names, so their addresses are prefixed with module.<name>.
+// Names must be unique, since plans with the same name would end up in the same module.
+func mergePlans(names []string, plans []*tfjson.Plan) (*tfjson.Plan, error) {
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			return nil, fmt.Errorf("can't merge several plans named %s", name)
+		}
+		seen[name] = true
+	}
+
+	merged := &tfjson.Plan{
+		Variables:     map[string]*tfjson.PlanVariable{},
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+		OutputChanges: map[string]*tfjson.Change{},
+		PriorState: &tfjson.State{
+			Values: &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+		},
+		Config: &tfjson.Config{
+			RootModule: &tfjson.ConfigModule{
+				ModuleCalls: map[string]*tfjson.ModuleCall{},
+			},
+		},
+	}
+
+	for i, plan := range plans {
+		prefix := fmt.Sprintf("module.%s", names[i])
+
+		if merged.FormatVersion == "" {
+			merged.FormatVersion = plan.FormatVersion
+			merged.TerraformVersion = plan.TerraformVersion
+		}
+
+		for name, v := range plan.Variables {
+			merged.Variables[fmt.Sprintf("%s.%s", prefix, name)] = v
+		}
+
+		for name, o := range plan.OutputChanges {
+			merged.OutputChanges[fmt.Sprintf("%s.output.%s", prefix, name)] = o
+		}
+
+		for _, rc := range plan.ResourceChanges {
+			merged.ResourceChanges = append(merged.ResourceChanges, prefixResourceChange(prefix, rc))
+		}
+
+		for _, rc := range plan.ResourceDrift {
+			merged.ResourceDrift = append(merged.ResourceDrift, prefixResourceChange(prefix, rc))
+		}
+
+		for _, ra := range plan.RelevantAttributes {
+			ra.Resource = prefixAddress(prefix, ra.Resource)
+			merged.RelevantAttributes = append(merged.RelevantAttributes, ra)
+		}
+
+		if plan.PlannedValues != nil && plan.PlannedValues.RootModule != nil {
+			merged.PlannedValues.RootModule.ChildModules = append(merged.PlannedValues.RootModule.ChildModules, prefixStateModule(prefix, plan.PlannedValues.RootModule))
+		}
+
+		if plan.PriorState != nil && plan.PriorState.Values != nil && plan.PriorState.Values.RootModule != nil {
+			merged.PriorState.Values.RootModule.ChildModules = append(merged.PriorState.Values.RootModule.ChildModules, prefixStateModule(prefix, plan.PriorState.Values.RootModule))
+		}
+
+		// Configuration references are relative to their module, so they can be reused unchanged
+		if plan.Config != nil && plan.Config.RootModule != nil {
+			merged.Config.RootModule.ModuleCalls[names[i]] = &tfjson.ModuleCall{
+				Source: names[i],
+				Module: plan.Config.RootModule,
+			}
+		}
+	}
+
+	return merged, nil
+}
+
+// prefixAddress prepends a module prefix to a resource or module address
+func prefixAddress(prefix string, address string) string {
+	if address == "" {
+		return prefix
+	}
+	return fmt.Sprintf("%s.%s", prefix, address)
+}
+
+func prefixResourceChange(prefix string, rc *tfjson.ResourceChange) *tfjson.ResourceChange {
+	prefixed := *rc
+	prefixed.Address = prefixAddress(prefix, rc.Address)
+	prefixed.ModuleAddress = prefixAddress(prefix, rc.ModuleAddress)
+	return &prefixed
+}
+
+func prefixStateModule(prefix string, module *tfjson.StateModule) *tfjson.StateModule {
+	prefixed := &tfjson.StateModule{
+		Address: prefixAddress(prefix, module.Address),
+	}
+
+	for _, rst := range module.Resources {
+		prefixedResource := *rst
+		prefixedResource.Address = prefixAddress(prefix, rst.Address)
+		prefixed.Resources = append(prefixed.Resources, &prefixedResource)
+	}
+
+	for _, childModule := range module.ChildModules {
+		prefixed.ChildModules = append(prefixed.ChildModules, prefixStateModule(prefix, childModule))
+	}
+
+	return prefixed
+}
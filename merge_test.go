@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestMergePlans(t *testing.T) {
+	newPlan := func() *tfjson.Plan {
+		return &tfjson.Plan{
+			FormatVersion: "1.1",
+			ResourceChanges: []*tfjson.ResourceChange{
+				resourceChange("aws_instance.web", "aws_instance", "aws", tfjson.ActionCreate),
+			},
+		}
+	}
+
+	merged, err := mergePlans([]string{"prod", "staging"}, []*tfjson.Plan{newPlan(), newPlan()})
+	if err != nil {
+		t.Fatalf("mergePlans() error = %s", err)
+	}
+
+	addresses := []string{}
+	for _, rc := range merged.ResourceChanges {
+		addresses = append(addresses, rc.Address)
+	}
+	if len(addresses) != 2 || addresses[0] != "module.prod.aws_instance.web" || addresses[1] != "module.staging.aws_instance.web" {
+		t.Errorf("merged addresses = %v, want module.prod.aws_instance.web and module.staging.aws_instance.web", addresses)
+	}
+
+	// Repeating a workspace would merge its resources into the same module twice
+	if _, err := mergePlans([]string{"prod", "prod"}, []*tfjson.Plan{newPlan(), newPlan()}); err == nil {
+		t.Error("mergePlans() with duplicate names succeeded, want an error")
+	}
+}
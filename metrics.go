@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// generationDurationBuckets are the upper bounds in seconds of the generation duration histogram
+var generationDurationBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// generationMetrics are the asset generation metrics of a configuration
+type generationMetrics struct {
+	Succeeded int
+	Failed    int
+	// Resources is the number of resource changes in the last successfully generated plan
+	Resources int
+	// BucketCounts counts the generations per duration bucket, the last one being +Inf
+	BucketCounts []int
+	DurationSum  float64
+}
+
+// Generation metrics are process wide, keyed by configuration name
+var (
+	metricsMu       sync.Mutex
+	metricsByConfig = map[string]*generationMetrics{}
+)
+
+// recordGeneration counts an asset generation of the rover that took d and failed with err, if not nil
+func (r *rover) recordGeneration(d time.Duration, err error) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	m, ok := metricsByConfig[r.Name]
+	if !ok {
+		m = &generationMetrics{BucketCounts: make([]int, len(generationDurationBuckets)+1)}
+		metricsByConfig[r.Name] = m
+	}
+
+	if err != nil {
+		m.Failed++
+	} else {
+		m.Succeeded++
+		if r.Plan != nil {
+			m.Resources = len(r.Plan.ResourceChanges)
+		}
+	}
+
+	seconds := d.Seconds()
+	bucket := sort.SearchFloat64s(generationDurationBuckets, seconds)
+	m.BucketCounts[bucket]++
+	m.DurationSum += seconds
+}
+
+// writeMetrics writes the generation metrics in the Prometheus text exposition format
+func writeMetrics(w io.Writer) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	names := make([]string, 0, len(metricsByConfig))
+	for name := range metricsByConfig {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "# HELP rover_asset_generations_total Number of asset generations by result.")
+	fmt.Fprintln(w, "# TYPE rover_asset_generations_total counter")
+	for _, name := range names {
+		m := metricsByConfig[name]
+		fmt.Fprintf(w, "rover_asset_generations_total{config=%s,result=\"success\"} %d\n", metricLabel(name), m.Succeeded)
+		fmt.Fprintf(w, "rover_asset_generations_total{config=%s,result=\"error\"} %d\n", metricLabel(name), m.Failed)
+	}
+
+	fmt.Fprintln(w, "# HELP rover_plan_resources Number of resource changes in the last generated plan.")
+	fmt.Fprintln(w, "# TYPE rover_plan_resources gauge")
+	for _, name := range names {
+		fmt.Fprintf(w, "rover_plan_resources{config=%s} %d\n", metricLabel(name), metricsByConfig[name].Resources)
+	}
+
+	fmt.Fprintln(w, "# HELP rover_asset_generation_duration_seconds Duration of asset generations, including planning.")
+	fmt.Fprintln(w, "# TYPE rover_asset_generation_duration_seconds histogram")
+	for _, name := range names {
+		m := metricsByConfig[name]
+
+		// Prometheus buckets are cumulative
+		count := 0
+		for i, upperBound := range generationDurationBuckets {
+			count += m.BucketCounts[i]
+			fmt.Fprintf(w, "rover_asset_generation_duration_seconds_bucket{config=%s,le=\"%s\"} %d\n", metricLabel(name), strconv.FormatFloat(upperBound, 'g', -1, 64), count)
+		}
+		count += m.BucketCounts[len(generationDurationBuckets)]
+		fmt.Fprintf(w, "rover_asset_generation_duration_seconds_bucket{config=%s,le=\"+Inf\"} %d\n", metricLabel(name), count)
+		fmt.Fprintf(w, "rover_asset_generation_duration_seconds_sum{config=%s} %s\n", metricLabel(name), strconv.FormatFloat(m.DurationSum, 'g', -1, 64))
+		fmt.Fprintf(w, "rover_asset_generation_duration_seconds_count{config=%s} %d\n", metricLabel(name), count)
+	}
+}
+
+var metricLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricLabel returns the quoted and escaped label value
+func metricLabel(value string) string {
+	return `"` + metricLabelReplacer.Replace(value) + `"`
+}
+
+// handleMetrics serves the generation metrics to Prometheus
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(w)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gzipResponseWriter compresses the response body once the status code allows a body
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.Header()
+	// Skip responses without a body and content which is already compressed
+	if code != http.StatusNoContent && code != http.StatusNotModified && h.Get("Content-Type") != "application/zip" {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", "gzip")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		// Sniff the uncompressed content, since net/http would sniff the compressed bytes
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.gz == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.gz.Write(b)
+}
+
+func (w *gzipResponseWriter) Close() error {
+	if w.gz == nil {
+		return nil
+	}
+	return w.gz.Close()
+}
+
+// gzipHandler compresses responses for clients accepting gzip encoding
+func gzipHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		// Websocket upgrades hijack the connection, which the gzip writer can't
+		if r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" || !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		// Byte ranges of the uncompressed content can't be served compressed
+		r.Header.Del("Range")
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.Close()
+
+		h.ServeHTTP(gw, r)
+	})
+}
+
+// staticCacheHandler sets ETags computed from the content of the embedded frontend files, which
+// only change with the build, so browsers revalidate them instead of downloading them again.
+// The file server responds with 304 Not Modified if the ETag matches.
+func staticCacheHandler(fe fs.FS, h http.Handler) http.Handler {
+	var etags sync.Map
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name == "" {
+			name = "index.html"
+		}
+
+		etag, ok := etags.Load(name)
+		if !ok {
+			// Directories and missing files are left to the file server
+			if content, err := fs.ReadFile(fe, name); err == nil {
+				sum := sha256.Sum256(content)
+				etag, _ = etags.LoadOrStore(name, fmt.Sprintf(`W/"%x"`, sum[:16]))
+			}
+		}
+
+		if etag != nil {
+			w.Header().Set("ETag", etag.(string))
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// notModified sets the ETag and Last-Modified headers of a response and responds with
+// 304 Not Modified if the request's If-None-Match or If-Modified-Since conditions match them
+func notModified(w http.ResponseWriter, r *http.Request, etag string, modTime time.Time) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				w.WriteHeader(http.StatusNotModified)
+				return true
+			}
+		}
+		return false
+	}
+
+	// HTTP dates have a resolution of a second
+	if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modTime.Truncate(time.Second).After(since) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// planPreviousAddresses holds the previous_address of the plan's resource changes, which
+// terraform-json doesn't decode
+type planPreviousAddresses struct {
+	ResourceChanges []struct {
+		Address         string `json:"address"`
+		PreviousAddress string `json:"previous_address"`
+	} `json:"resource_changes"`
+}
+
+// readPreviousAddresses records the previous address of the resources moved by moved blocks
+// in the plan JSON. Addresses are prefixed with prefix, if set, to match merged plans.
+func (r *rover) readPreviousAddresses(prefix string, planJson []byte) {
+	var p planPreviousAddresses
+	if err := json.Unmarshal(planJson, &p); err != nil {
+		// The plan itself failed or will fail to parse, which is reported instead
+		return
+	}
+
+	if r.PreviousAddresses == nil {
+		r.PreviousAddresses = map[string]string{}
+	}
+
+	for _, rc := range p.ResourceChanges {
+		if rc.PreviousAddress == "" || rc.PreviousAddress == rc.Address {
+			continue
+		}
+
+		address, previousAddress := rc.Address, rc.PreviousAddress
+		if prefix != "" {
+			address = prefixAddress(prefix, address)
+			previousAddress = prefixAddress(prefix, previousAddress)
+		}
+		r.PreviousAddresses[address] = previousAddress
+	}
+}
+
+// movedFrom returns the previous address of a resource that a moved block moved without
+// changing it otherwise, which Terraform plans as a no-op with a different previous address
+func (r *rover) movedFrom(rc *tfjson.ResourceChange) string {
+	if rc.Change == nil || !rc.Change.Actions.NoOp() {
+		return ""
+	}
+	return r.PreviousAddresses[rc.Address]
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// configNamePattern restricts configuration names to a single URL path segment
+var configNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// reservedConfigNames would shadow the routes shared by all configurations
+var reservedConfigNames = []string{"api", "css", "debug", "download", "health", "img", "js", "metrics"}
+
+var configIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Rover</title>
+<link rel="stylesheet" href="/chota.min.css">
+</head>
+<body>
+<div class="container">
+<h1>Rover</h1>
+<ul>
+{{- range .}}
+<li><a href="/{{.Name}}/">{{.Name}}</a> ({{.WorkingDir}})</li>
+{{- end}}
+</ul>
+</div>
+</body>
+</html>
+`))
+
+// multiConfig returns a rover for every name=workingDir entry, configured like r otherwise
+func (r *rover) multiConfig(specs []string) ([]*rover, error) {
+	configs := []*rover{}
+	names := map[string]bool{}
+
+	for _, spec := range specs {
+		name, workingDir, ok := strings.Cut(spec, "=")
+		if !ok || workingDir == "" {
+			return nil, fmt.Errorf("invalid config %q: must be name=workingDir", spec)
+		}
+		if !configNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid config name %q: must only contain letters, digits, - and _", name)
+		}
+		for _, reserved := range reservedConfigNames {
+			if name == reserved {
+				return nil, fmt.Errorf("invalid config name %q: reserved", name)
+			}
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicate config name %q", name)
+		}
+		names[name] = true
+
+		c := *r
+		c.Name = name
+		c.WorkingDir = workingDir
+		c.Plan = nil
+		c.RSO = nil
+		c.Map = nil
+		c.Graph = Graph{}
+		c.Diagnostics = []Diagnostic{}
+		c.assetsMu = &sync.RWMutex{}
+		c.refreshMu = &sync.Mutex{}
+		c.progress = newProgressHub()
+
+		configs = append(configs, &c)
+	}
+
+	return configs, nil
+}
+
+// serveConfigs generates the assets of every name=workingDir configuration and serves them all
+func (r *rover) serveConfigs(specs []string, ipPort string) {
+	if r.GenImage {
+		log.Fatal("--genImage can't be combined with --config")
+	}
+
+	configs, err := r.multiConfig(specs)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	for _, c := range configs {
+		logStatus(COLOR_CYAN, "Generating assets for %s (%s)...", c.Name, c.WorkingDir)
+
+		if err := c.generateAssets(); err != nil {
+			log.Fatalf("%s: %s", c.Name, err)
+		}
+		c.reportProgress(PROGRESS_DONE)
+
+		if c.NotifyURL != "" {
+			if err := c.notify(); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+
+	logStatus(COLOR_GREEN, "Done generating assets.")
+
+	fe, _ := embeddedFrontend()
+	if err := startMultiServer(ipPort, fe, configs); err != nil {
+		log.Fatalf("Could not start server: %s\n", err.Error())
+	}
+}
+
+// startMultiServer serves every configuration under /<name>/ and lists them on the index page
+func startMultiServer(ipPort string, fe fs.FS, configs []*rover) error {
+	m := http.NewServeMux()
+	// All configurations share the server timeouts and rate limit
+	s := configs[0].httpServer(ipPort, configs[0].rateLimitHandler(gzipHandler(m)))
+
+	// The frontend references its static files by absolute path, so they are shared by all configurations
+	fileServer := staticCacheHandler(fe, http.FileServer(http.FS(fe)))
+	m.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			fileServer.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := configIndexTemplate.Execute(w, configs); err != nil {
+			log.Printf("Error producing index page: %s", err)
+		}
+	})
+	m.HandleFunc("/health", handleHealth)
+	m.HandleFunc("/api/version", handleVersion)
+	m.HandleFunc("/metrics", handleMetrics)
+	// All configurations share the frontend config
+	m.HandleFunc("/config.json", configs[0].handleFrontendConfig)
+
+	for _, c := range configs {
+		if c.Profile {
+			registerPprof(m)
+			break
+		}
+	}
+
+	for _, c := range configs {
+		c.registerConfig(m, fe)
+	}
+
+	l, err := net.Listen("tcp", ipPort)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// With port 0, the OS picks a free port
+	s.Addr = l.Addr().String()
+	logStatus(COLOR_GREEN, "Rover is running on %s (%s) with %d configurations", s.Addr, serverURL(s.Addr), len(configs))
+
+	return s.Serve(l)
+}
+
+// registerConfig serves the frontend and assets of a configuration under /<name>/.
+// The frontend loads the assets from js files like in standalone mode, since it
+// requests them from the API by absolute path.
+func (ro *rover) registerConfig(m *http.ServeMux, fe fs.FS) {
+	prefix := fmt.Sprintf("/%s", ro.Name)
+
+	ro.registerAssets(m, prefix, fe)
+
+	m.HandleFunc(prefix+"/", func(w http.ResponseWriter, r *http.Request) {
+		file := strings.TrimPrefix(r.URL.Path, prefix+"/")
+
+		if file == "" {
+			index, err := standaloneIndex(fe, prefix)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Error producing index page: %s", err), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			io.WriteString(w, index)
+			return
+		}
+
+		frontendConfig, err := ro.frontendConfig()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error producing frontend config: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		snap := ro.snapshot()
+		assets := map[string]interface{}{
+			"map.js":            snap.Map,
+			"rso.js":            snap.RSO,
+			"graph.js":          snap.Graph,
+			"diagnostics.js":    snap.Diagnostics,
+			"frontendConfig.js": frontendConfig,
+		}
+
+		asset, ok := assets[file]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		content, err := standaloneJS(strings.TrimSuffix(file, ".js"), asset)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Write(content)
+	})
+}
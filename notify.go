@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Notification is the body POSTed to --notifyURL once assets are generated
+type Notification struct {
+	Status  string  `json:"status"`
+	Name    string  `json:"name"`
+	Version string  `json:"version"`
+	Summary Summary `json:"summary"`
+}
+
+// notify POSTs the RSO summary to r.NotifyURL, retrying up to r.NotifyRetries times on network
+// errors and server errors. Other responses won't change on a retry, so they fail right away.
+func (r *rover) notify() error {
+	body, err := json.Marshal(Notification{
+		Status:  "ready",
+		Name:    r.Name,
+		Version: VERSION,
+		Summary: r.summary(),
+	})
+	if err != nil {
+		return fmt.Errorf("error producing notification JSON: %s", err)
+	}
+
+	client := &http.Client{Timeout: r.NotifyTimeout}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := client.Post(r.NotifyURL, "application/json", bytes.NewReader(body))
+		if err == nil {
+			resp.Body.Close()
+			log.Printf("Notified %s (%s)", r.NotifyURL, resp.Status)
+
+			if resp.StatusCode < 300 {
+				return nil
+			}
+			err = fmt.Errorf("unexpected response status %s", resp.Status)
+			if resp.StatusCode < 500 {
+				return fmt.Errorf("unable to notify %s: %s", r.NotifyURL, err)
+			}
+		}
+
+		if attempt > r.NotifyRetries {
+			return fmt.Errorf("unable to notify %s: %s", r.NotifyURL, err)
+		}
+
+		log.Printf("Notification failed, retrying (%d/%d)...", attempt, r.NotifyRetries)
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNotifyRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		wantErr      bool
+		wantRequests int32
+	}{
+		{name: "success", status: http.StatusOK, wantRequests: 1},
+		{name: "client error", status: http.StatusBadRequest, wantErr: true, wantRequests: 1},
+		{name: "server error", status: http.StatusServiceUnavailable, wantErr: true, wantRequests: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				w.WriteHeader(tt.status)
+			}))
+			defer s.Close()
+
+			r := &rover{NotifyURL: s.URL, NotifyRetries: 1}
+			err := r.notify()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.wantRequests {
+				t.Errorf("notify() sent %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	OBJECT_URL_S3  string = "s3"
+	OBJECT_URL_GCS string = "gs"
+)
+
+// GCS_READ_SCOPE is the OAuth scope requested for downloading objects from Google Cloud Storage
+const GCS_READ_SCOPE = "https://www.googleapis.com/auth/devstorage.read_only"
+
+// S3_DEFAULT_REGION is used if no region is configured, S3 redirects requests for buckets in other regions
+const S3_DEFAULT_REGION = "us-east-1"
+
+// errObjectNotFound and errObjectAccessDenied distinguish why an object couldn't be downloaded
+var (
+	errObjectNotFound     = errors.New("object not found")
+	errObjectAccessDenied = errors.New("access denied")
+)
+
+// isObjectURL returns true if path is an s3:// or gs:// URL rather than a local path
+func isObjectURL(path string) bool {
+	return strings.HasPrefix(path, OBJECT_URL_S3+"://") || strings.HasPrefix(path, OBJECT_URL_GCS+"://")
+}
+
+// downloadObject returns the content of the object at an s3://bucket/key or gs://bucket/object URL.
+// Credentials are loaded from the environment, shared config files or the instance like the CLIs do.
+func downloadObject(objectURL string) ([]byte, error) {
+	u, err := url.Parse(objectURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL (%s): %s", objectURL, err)
+	}
+
+	bucket := u.Host
+	key := strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("invalid URL (%s): must be %s://bucket/key", objectURL, u.Scheme)
+	}
+
+	switch u.Scheme {
+	case OBJECT_URL_S3:
+		return downloadS3Object(bucket, key)
+	case OBJECT_URL_GCS:
+		return downloadGCSObject(bucket, key)
+	}
+
+	return nil, fmt.Errorf("invalid URL (%s): unsupported scheme %s", objectURL, u.Scheme)
+}
+
+func downloadS3Object(bucket string, key string) ([]byte, error) {
+	ctx := context.Background()
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS config: %s", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = S3_DEFAULT_REGION
+	}
+
+	out, err := s3.NewFromConfig(cfg).GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			switch apiErr.ErrorCode() {
+			case "NoSuchKey", "NoSuchBucket", "NotFound":
+				return nil, fmt.Errorf("%w (%s)", errObjectNotFound, apiErr.ErrorCode())
+			case "AccessDenied", "Forbidden", "InvalidAccessKeyId", "SignatureDoesNotMatch", "ExpiredToken":
+				return nil, fmt.Errorf("%w (%s)", errObjectAccessDenied, apiErr.ErrorCode())
+			}
+		}
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	return io.ReadAll(out.Body)
+}
+
+func downloadGCSObject(bucket string, object string) ([]byte, error) {
+	ctx := context.Background()
+
+	// The emulator doesn't authenticate requests, like with the Google Cloud SDKs
+	endpoint := "https://storage.googleapis.com"
+	client := http.DefaultClient
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+		endpoint = host
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+	} else {
+		var err error
+		client, err = google.DefaultClient(ctx, GCS_READ_SCOPE)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find Google Cloud credentials: %s", err)
+		}
+	}
+
+	resp, err := client.Get(fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", strings.TrimSuffix(endpoint, "/"), url.PathEscape(bucket), url.PathEscape(object)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, errObjectNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("%w (%s)", errObjectAccessDenied, resp.Status)
+	}
+
+	return nil, fmt.Errorf("unexpected status %s", resp.Status)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// outputsOnly returns true if the plan changes outputs without changing any resources
+func (r *rover) outputsOnly() bool {
+	if r.Plan == nil {
+		return false
+	}
+
+	for _, rc := range r.Plan.ResourceChanges {
+		if rc.Change != nil && !rc.Change.Actions.NoOp() && !rc.Change.Actions.Read() {
+			return false
+		}
+	}
+
+	for _, output := range r.Plan.OutputChanges {
+		if output != nil && !output.Actions.NoOp() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// showOutputs returns true if the output changes are added to the RSO and graph, which they are
+// with --showOutputs and when the plan only changes outputs
+func (r *rover) showOutputs() bool {
+	return r.ShowOutputs || r.outputsOnly()
+}
+
+// addOutputStates adds the root module output changes to the RSO states under their graph node IDs
+func (r *rover) addOutputStates(rs map[string]*StateOverview) {
+	for outputName, output := range r.Plan.OutputChanges {
+		rs[fmt.Sprintf("output.%s", outputName)] = &StateOverview{
+			Change: *output,
+			Type:   ResourceTypeOutput,
+		}
+	}
+}
+
+// addOutputNodes adds the change of every root module output to its node, adding the node if the
+// output isn't configured, and connects it to the nodes it references according to the plan
+func (r *rover) addOutputNodes(nodes []Node, edges []Edge) ([]Node, []Edge) {
+	index := make(map[string]int)
+	for i, n := range nodes {
+		index[n.Data.ID] = i
+	}
+
+	names := make([]string, 0, len(r.Plan.OutputChanges))
+	for name := range r.Plan.OutputChanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	basePath := strings.ReplaceAll(r.Map.Path, "./", "")
+
+	for _, name := range names {
+		id := fmt.Sprintf("output.%s", name)
+
+		change := ""
+		if actions := r.Plan.OutputChanges[name].Actions; len(actions) > 0 {
+			change = string(actions[0])
+			if len(actions) > 1 {
+				change = string(ActionReplace)
+			}
+		}
+
+		if i, ok := index[id]; ok {
+			nodes[i].Data.Change = change
+			nodes[i].Classes = strings.TrimSpace(fmt.Sprintf("output %s", change))
+		} else {
+			index[id] = len(nodes)
+			nodes = append(nodes, Node{
+				Data: NodeData{
+					ID:     id,
+					Label:  name,
+					Type:   ResourceTypeOutput,
+					Parent: basePath,
+					Change: change,
+				},
+				Classes: strings.TrimSpace(fmt.Sprintf("output %s", change)),
+			})
+		}
+
+		if r.Plan.Config == nil || r.Plan.Config.RootModule == nil || r.Plan.Config.RootModule.Outputs[name] == nil {
+			continue
+		}
+		expression := r.Plan.Config.RootModule.Outputs[name].Expression
+		if expression == nil {
+			continue
+		}
+
+		// Terraform lists both the attribute and the object for each reference, e.g. a.b.id and a.b
+		targets := make(map[string]bool)
+		for _, reference := range expression.References {
+			target := reference
+			for {
+				if _, ok := index[target]; ok || !strings.Contains(target, ".") {
+					break
+				}
+				target = target[:strings.LastIndex(target, ".")]
+			}
+
+			targetIndex, ok := index[target]
+			if !ok || target == id || targets[target] {
+				continue
+			}
+			targets[target] = true
+
+			edges = append(edges, Edge{
+				Data: EdgeData{
+					ID:       fmt.Sprintf("%s->%s", id, target),
+					Source:   id,
+					Target:   target,
+					Gradient: fmt.Sprintf("%s %s", OUTPUT_COLOR, getResourceColor(nodes[targetIndex].Data.Type)),
+				},
+				Classes: "edge",
+			})
+		}
+	}
+
+	return nodes, dedupeEdges(edges)
+}
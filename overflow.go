@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+const (
+	OVERFLOW_WARN     string = "warn"
+	OVERFLOW_ERROR    string = "error"
+	OVERFLOW_COLLAPSE string = "collapse"
+)
+
+// checkResourceCount enforces --maxResources on the graph, either warning, failing or
+// collapsing every module into a single node. Only the graph is collapsed, the map and
+// the resource API still list every resource.
+func (r *rover) checkResourceCount(g *Graph) error {
+	count := len(r.Plan.ResourceChanges)
+	log.Printf("Plan contains %d resources", count)
+
+	if r.MaxResources <= 0 || count <= r.MaxResources {
+		return nil
+	}
+
+	switch r.OnOverflow {
+	case OVERFLOW_ERROR:
+		return fmt.Errorf("plan contains %d resources, more than the maximum of %d (use --maxResources or --onOverflow collapse)", count, r.MaxResources)
+	case OVERFLOW_COLLAPSE:
+		log.Printf("Plan contains more than %d resources, collapsing modules in the graph...", r.MaxResources)
+		collapseModules(g)
+	default:
+		logStatus(COLOR_YELLOW, "WARNING: plan contains %d resources, more than %d, the graph may be slow to render (use --onOverflow collapse to collapse modules)", count, r.MaxResources)
+	}
+
+	return nil
+}
+
+// topModule returns the address of the top level module instance containing id,
+// or an empty string if id is not within a module
+func topModule(id string) string {
+	if !strings.HasPrefix(id, "module.") {
+		return ""
+	}
+
+	depth := 0
+	for i := len("module."); i < len(id); i++ {
+		switch id[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.', ' ':
+			if depth == 0 {
+				return id[:i]
+			}
+		}
+	}
+
+	return id
+}
+
+// collapseModules replaces everything inside of top level modules by the module node itself
+func collapseModules(g *Graph) {
+	nodes := []Node{}
+	for _, n := range g.Nodes {
+		if m := topModule(n.Data.ID); m == "" || m == n.Data.ID {
+			nodes = append(nodes, n)
+		}
+	}
+
+	edges := []Edge{}
+	for _, e := range g.Edges {
+		source, target := e.Data.Source, e.Data.Target
+		if m := topModule(source); m != "" {
+			source = m
+		}
+		if m := topModule(target); m != "" {
+			target = m
+		}
+
+		if source == target {
+			continue
+		}
+
+		e.Data.ID = fmt.Sprintf("%s->%s", source, target)
+		e.Data.Source = source
+		e.Data.Target = target
+
+		edges = append(edges, e)
+	}
+
+	g.Nodes = nodes
+	g.Edges = dedupeEdges(edges)
+}
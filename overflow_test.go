@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestCheckResourceCount(t *testing.T) {
+	newGraph := func() Graph {
+		return Graph{
+			Nodes: []Node{
+				{Data: NodeData{ID: "aws_instance.web"}},
+				{Data: NodeData{ID: "module.vpc"}},
+				{Data: NodeData{ID: "module.vpc.aws_vpc.main"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		onOverflow string
+		wantErr    bool
+		wantNodes  int
+	}{
+		{onOverflow: OVERFLOW_WARN, wantNodes: 3},
+		{onOverflow: OVERFLOW_ERROR, wantErr: true, wantNodes: 3},
+		{onOverflow: OVERFLOW_COLLAPSE, wantNodes: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.onOverflow, func(t *testing.T) {
+			r := &rover{
+				Plan: &tfjson.Plan{ResourceChanges: []*tfjson.ResourceChange{
+					{Address: "aws_instance.web"},
+					{Address: "module.vpc.aws_vpc.main"},
+				}},
+				MaxResources: 1,
+				OnOverflow:   tt.onOverflow,
+			}
+			g := newGraph()
+
+			err := r.checkResourceCount(&g)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkResourceCount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(g.Nodes) != tt.wantNodes {
+				t.Errorf("checkResourceCount() left %d nodes, want %d", len(g.Nodes), tt.wantNodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// PARTIAL_PLAN_FORMAT_VERSION is assumed for partial plans exported without a format version
+const PARTIAL_PLAN_FORMAT_VERSION = "1.0"
+
+// moduleCallPattern matches a module call, including its count or for_each index, in a module address
+var moduleCallPattern = regexp.MustCompile(`module\.([^.\[]+)(\[[^\]]*\])?`)
+
+// completePartialPlanJSON adds a format version to a bare resource_changes array, or to an object
+// with resource_changes but without format version, so it can be unmarshaled into a plan
+func completePartialPlanJSON(content []byte) ([]byte, error) {
+	var resourceChanges []json.RawMessage
+	if err := json.Unmarshal(content, &resourceChanges); err == nil {
+		return json.Marshal(map[string]interface{}{
+			"format_version":   PARTIAL_PLAN_FORMAT_VERSION,
+			"resource_changes": resourceChanges,
+		})
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(content, &object); err != nil {
+		// Let the plan unmarshaling report the error
+		return content, nil
+	}
+
+	_, hasFormatVersion := object["format_version"]
+	_, hasResourceChanges := object["resource_changes"]
+	if hasFormatVersion || !hasResourceChanges {
+		return content, nil
+	}
+
+	object["format_version"] = json.RawMessage(`"` + PARTIAL_PLAN_FORMAT_VERSION + `"`)
+	return json.Marshal(object)
+}
+
+// isPartialPlan returns true if the plan contains resource changes, but not the planned values
+// the visualization is built from
+func isPartialPlan(plan *tfjson.Plan) bool {
+	if len(plan.ResourceChanges) == 0 {
+		return false
+	}
+
+	return plan.PlannedValues == nil || plan.PlannedValues.RootModule == nil
+}
+
+// hasConfig returns true if the plan contains the configuration of its root module
+func hasConfig(plan *tfjson.Plan) bool {
+	return plan.Config != nil && plan.Config.RootModule != nil
+}
+
+// completePartialPlan derives the planned values of a partial plan from its resource changes.
+// A configuration in the plan is kept, so references are read from its expressions as usual.
+// Otherwise the configuration is derived from the addresses of the resource changes too, which
+// don't contain expressions, so the plan has no references between resources.
+func completePartialPlan(plan *tfjson.Plan) {
+	rootState := &tfjson.StateModule{}
+	rootConfig := &tfjson.ConfigModule{}
+	stateModules := map[string]*tfjson.StateModule{"": rootState}
+	// Instances of the same module call share its configuration
+	configResources := map[*tfjson.ConfigModule]map[string]bool{}
+
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change == nil {
+			continue
+		}
+
+		stateModule := rootState
+		configModule := rootConfig
+		moduleAddress := ""
+		for _, call := range moduleCallPattern.FindAllStringSubmatch(rc.ModuleAddress, -1) {
+			if moduleAddress != "" {
+				moduleAddress += "."
+			}
+			moduleAddress += call[0]
+
+			child, ok := stateModules[moduleAddress]
+			if !ok {
+				child = &tfjson.StateModule{Address: moduleAddress}
+				stateModules[moduleAddress] = child
+				stateModule.ChildModules = append(stateModule.ChildModules, child)
+			}
+			stateModule = child
+
+			name := call[1]
+			if configModule.ModuleCalls == nil {
+				configModule.ModuleCalls = map[string]*tfjson.ModuleCall{}
+			}
+			if _, ok := configModule.ModuleCalls[name]; !ok {
+				configModule.ModuleCalls[name] = &tfjson.ModuleCall{Module: &tfjson.ConfigModule{}}
+			}
+			configModule = configModule.ModuleCalls[name].Module
+		}
+
+		// Resources to be deleted are only in the prior state
+		values := rc.Change.After
+		if rc.Change.Actions.Delete() {
+			values = rc.Change.Before
+		}
+
+		stateModule.Resources = append(stateModule.Resources, &tfjson.StateResource{
+			Address:         rc.Address,
+			Mode:            rc.Mode,
+			Type:            rc.Type,
+			Name:            rc.Name,
+			Index:           rc.Index,
+			ProviderName:    rc.ProviderName,
+			AttributeValues: attributeValues(values),
+		})
+
+		configAddress := rc.Type + "." + rc.Name
+		if rc.Mode == tfjson.DataResourceMode {
+			configAddress = "data." + configAddress
+		}
+		if configResources[configModule] == nil {
+			configResources[configModule] = map[string]bool{}
+		}
+		if !configResources[configModule][configAddress] {
+			configResources[configModule][configAddress] = true
+			configModule.Resources = append(configModule.Resources, &tfjson.ConfigResource{
+				Address: configAddress,
+				Mode:    rc.Mode,
+				Type:    rc.Type,
+				Name:    rc.Name,
+			})
+		}
+	}
+
+	plan.PlannedValues = &tfjson.StateValues{RootModule: rootState}
+	if !hasConfig(plan) {
+		plan.Config = &tfjson.Config{RootModule: rootConfig}
+	}
+}
+
+// attributeValues returns the values as state attributes, which are always an object
+func attributeValues(values interface{}) map[string]interface{} {
+	if attributes, ok := values.(map[string]interface{}); ok {
+		return attributes
+	}
+	return map[string]interface{}{}
+}
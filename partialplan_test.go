@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// partialPlanFixture writes testdata/plan.json without the given top-level keys
+func partialPlanFixture(t *testing.T, without ...string) string {
+	t.Helper()
+
+	content, err := os.ReadFile("testdata/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plan map[string]json.RawMessage
+	if err := json.Unmarshal(content, &plan); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range without {
+		delete(plan, key)
+	}
+	content, err = json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "partial.json")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPartialPlanReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		without []string
+		want    bool
+	}{
+		{
+			name:    "full plan",
+			without: nil,
+			want:    true,
+		},
+		{
+			name:    "configuration without planned values",
+			without: []string{"planned_values"},
+			want:    true,
+		},
+		{
+			name:    "resource changes only",
+			without: []string{"planned_values", "configuration"},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testRover(t, "plan.json")
+			r.PlanJSONPath = partialPlanFixture(t, tt.without...)
+			if err := r.generateAssets(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := len(r.Plan.PlannedValues.RootModule.Resources); got != 4 {
+				t.Errorf("got %d planned resources, want 4", got)
+			}
+
+			referenced := false
+			for _, e := range r.Graph.Edges {
+				if e.Data.Source == "null_resource.a" && e.Data.Target == "null_resource.b" {
+					referenced = true
+				}
+			}
+			if referenced != tt.want {
+				t.Errorf("null_resource.a references null_resource.b is %t, want %t; edges %+v", referenced, tt.want, r.Graph.Edges)
+			}
+		})
+	}
+}
+
+func TestBarePartialPlan(t *testing.T) {
+	content, err := os.ReadFile("testdata/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plan map[string]json.RawMessage
+	if err := json.Unmarshal(content, &plan); err != nil {
+		t.Fatal(err)
+	}
+
+	r := testRover(t, "plan.json")
+	r.PlanJSONPath = filepath.Join(t.TempDir(), "resource_changes.json")
+	if err := os.WriteFile(r.PlanJSONPath, plan["resource_changes"], 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.generateAssets(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(r.Plan.PlannedValues.RootModule.Resources); got != 4 {
+		t.Errorf("got %d planned resources, want 4", got)
+	}
+	if got := len(r.Plan.Config.RootModule.Resources); got != 3 {
+		t.Errorf("got %d configured resources, want 3", got)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+// TF_CLI_ARGS_PLAN is the environment variable Terraform reads extra plan arguments from
+const TF_CLI_ARGS_PLAN = "TF_CLI_ARGS_plan"
+
+// RESERVED_PLAN_ARGS are the plan flags Rover sets itself, with the Rover flag to use instead
+var RESERVED_PLAN_ARGS = map[string]string{
+	"out":      "--keepPlan",
+	"var":      "--tfVar",
+	"var-file": "--tfVarsFile",
+	"lock":     "--lock",
+	"json":     "",
+	"input":    "",
+	"no-color": "",
+}
+
+// parsePlanArgs converts --tfPlanArg flags to plan options where tfexec models them, returning
+// the other flags to pass to Terraform through TF_CLI_ARGS_plan. The leading dash of the flags is
+// optional, since Rover's argument parser would mistake values starting with one for flags.
+func parsePlanArgs(args []string) ([]tfexec.PlanOption, []string, error) {
+	var options []tfexec.PlanOption
+	var cliArgs []string
+
+	for _, arg := range args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name == "" {
+			return nil, nil, fmt.Errorf("%q is not a flag", arg)
+		}
+
+		if alternative, ok := RESERVED_PLAN_ARGS[name]; ok {
+			if alternative != "" {
+				return nil, nil, fmt.Errorf("-%s is set by Rover, use %s instead", name, alternative)
+			}
+			return nil, nil, fmt.Errorf("-%s is set by Rover", name)
+		}
+
+		switch name {
+		case "target", "replace", "state", "lock-timeout", "parallelism":
+			if !hasValue || value == "" {
+				return nil, nil, fmt.Errorf("-%s requires a value (%s=...)", name, name)
+			}
+		}
+
+		switch name {
+		case "target":
+			options = append(options, tfexec.Target(value))
+		case "replace":
+			options = append(options, tfexec.Replace(value))
+		case "state":
+			options = append(options, tfexec.State(value))
+		case "lock-timeout":
+			options = append(options, tfexec.LockTimeout(value))
+		case "parallelism":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, nil, fmt.Errorf("-parallelism must be a positive integer, got %q", value)
+			}
+			options = append(options, tfexec.Parallelism(n))
+		case "refresh", "destroy":
+			b := true
+			if hasValue {
+				var err error
+				b, err = strconv.ParseBool(value)
+				if err != nil {
+					return nil, nil, fmt.Errorf("-%s must be true or false, got %q", name, value)
+				}
+			}
+			if name == "refresh" {
+				options = append(options, tfexec.Refresh(b))
+			} else {
+				options = append(options, tfexec.Destroy(b))
+			}
+		default:
+			cliArg := "-" + name
+			if hasValue {
+				cliArg = fmt.Sprintf("-%s=%s", name, value)
+			}
+			cliArgs = append(cliArgs, cliArg)
+		}
+	}
+
+	return options, cliArgs, nil
+}
+
+// setPlanCLIArgs adds the --tfPlanArg flags tfexec doesn't model to TF_CLI_ARGS_plan, which
+// Terraform inherits. tfexec refuses TF_CLI_ARGS_ variables set with tf.SetEnv.
+func setPlanCLIArgs(cliArgs []string) error {
+	if len(cliArgs) == 0 {
+		return nil
+	}
+
+	args := []string{}
+	// Keep the arguments the user set themselves
+	if existing := os.Getenv(TF_CLI_ARGS_PLAN); existing != "" {
+		args = append(args, existing)
+	}
+	for _, arg := range cliArgs {
+		args = append(args, shellQuote(arg))
+	}
+
+	if err := os.Setenv(TF_CLI_ARGS_PLAN, strings.Join(args, " ")); err != nil {
+		return fmt.Errorf("unable to set %s: %s", TF_CLI_ARGS_PLAN, err)
+	}
+
+	return nil
+}
+
+// shellQuote quotes arg with single quotes if needed, since Terraform splits TF_CLI_ARGS_plan
+// like a shell would
+func shellQuote(arg string) string {
+	if !strings.ContainsAny(arg, " \t\n'\"\\$`") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
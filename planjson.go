@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const (
+	PLAN_JSON_FORMAT_RAW      string = "raw"
+	PLAN_JSON_FORMAT_TFC      string = "tfc"
+	PLAN_JSON_FORMAT_ATLANTIS string = "atlantis"
+)
+
+// planJSONEnvelopes lists the paths of keys leading to the plan in each envelope format, in order of preference
+var planJSONEnvelopes = map[string][][]string{
+	// JSON:API document as returned by the Terraform Cloud API
+	PLAN_JSON_FORMAT_TFC:      {{"data", "attributes"}, {"data"}},
+	PLAN_JSON_FORMAT_ATLANTIS: {{"plan"}, {"plan_json"}},
+}
+
+// invalidModuleNameChars matches the characters that aren't allowed in module names
+var invalidModuleNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// planModuleName returns the name of the module a merged plan file becomes. It's the file name without
+// its extension, with characters Terraform doesn't allow in identifiers replaced by underscores.
+func planModuleName(file string) string {
+	name := invalidModuleNameChars.ReplaceAllString(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)), "_")
+
+	// Identifiers start with a letter or an underscore
+	if name == "" || !(name[0] == '_' || (name[0] >= 'A' && name[0] <= 'Z') || (name[0] >= 'a' && name[0] <= 'z')) {
+		name = "_" + name
+	}
+
+	return name
+}
+
+// getJSONPlans reads the plan JSON file at r.PlanJSONPath. If it is a directory, every
+// *.json file in it is read and the plans are merged, each named after its file.
+// An s3:// or gs:// URL is downloaded from object storage.
+func (r *rover) getJSONPlans() error {
+	if isObjectURL(r.PlanJSONPath) {
+		log.Printf("Downloading JSON plan from %s...", r.PlanJSONPath)
+
+		planJson, err := downloadObject(r.PlanJSONPath)
+		if err != nil {
+			return fmt.Errorf("unable to read Plan (%s): %s", r.PlanJSONPath, err)
+		}
+
+		plan, err := r.parseJSONPlan(r.PlanJSONPath, "", planJson)
+		if err != nil {
+			return err
+		}
+		r.Plan = plan
+		return nil
+	}
+
+	info, err := os.Stat(r.PlanJSONPath)
+	if err != nil {
+		return fmt.Errorf("unable to read Plan (%s): %s", r.PlanJSONPath, err)
+	}
+
+	if !info.IsDir() {
+		log.Println("Using provided JSON plan...")
+
+		plan, err := r.readJSONPlan(r.PlanJSONPath, "")
+		if err != nil {
+			return err
+		}
+		r.Plan = plan
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(r.PlanJSONPath, "*.json"))
+	if err != nil {
+		return fmt.Errorf("unable to list Plans in %s: %s", r.PlanJSONPath, err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no JSON plans found in %s", r.PlanJSONPath)
+	}
+	sort.Strings(files)
+
+	log.Printf("Using %d provided JSON plans from %s...", len(files), r.PlanJSONPath)
+
+	names := []string{}
+	nameFiles := map[string]string{}
+	for _, file := range files {
+		name := planModuleName(file)
+		if other, ok := nameFiles[name]; ok {
+			return fmt.Errorf("plans %s and %s would both be merged as module.%s, please rename one of them", other, file, name)
+		}
+		nameFiles[name] = file
+		names = append(names, name)
+	}
+
+	plans := []*tfjson.Plan{}
+	for i, file := range files {
+		// Merged plans prefix addresses with a module named after the file
+		prefix := ""
+		if len(files) > 1 {
+			prefix = fmt.Sprintf("module.%s", names[i])
+		}
+
+		plan, err := r.readJSONPlan(file, prefix)
+		if err != nil {
+			return err
+		}
+		plans = append(plans, plan)
+	}
+
+	if len(plans) == 1 {
+		r.Plan = plans[0]
+		return nil
+	}
+
+	log.Printf("Merging %d plans...", len(plans))
+	plan, err := mergePlans(names, plans)
+	if err != nil {
+		return err
+	}
+	r.Plan = plan
+
+	return nil
+}
+
+// readJSONPlan reads a single plan JSON file, unwrapping the plan from its r.PlanJSONFormat envelope
+func (r *rover) readJSONPlan(path string, prefix string) (*tfjson.Plan, error) {
+	planJsonFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read Plan (%s): %s", path, err)
+	}
+	defer planJsonFile.Close()
+
+	planJson, err := io.ReadAll(planJsonFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read Plan (%s): %s", path, err)
+	}
+
+	if isBinaryPlan(planJson) {
+		logStatus(COLOR_YELLOW, "WARNING: %s is a binary plan file rather than plan JSON, reading it with %s instead", path, r.Engine.Name())
+		return r.readBinaryPlan(path, prefix)
+	}
+
+	return r.parseJSONPlan(path, prefix, planJson)
+}
+
+// isBinaryPlan returns true if content is a plan file saved by plan -out, which is a zip archive
+func isBinaryPlan(content []byte) bool {
+	return bytes.HasPrefix(content, []byte("PK\x03\x04"))
+}
+
+// readBinaryPlan reads a plan file with the Terraform binary, which needs the working directory
+// the plan was created in to be initialized
+func (r *rover) readBinaryPlan(path string, prefix string) (*tfjson.Plan, error) {
+	tf, err := r.newTerraform()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.checkVersion(tf); err != nil {
+		return nil, err
+	}
+
+	plan, err := r.showPlanFile(tf, path, prefix)
+	if err != nil {
+		return nil, withKind(ErrPlanParse, fmt.Errorf("unable to read Plan (%s): %w", path, err))
+	}
+
+	return plan, nil
+}
+
+// parseJSONPlan parses the plan JSON read from path. The previous addresses of moved resources
+// are recorded with prefix, the module prefix of the plan's addresses once merged.
+func (r *rover) parseJSONPlan(path string, prefix string, planJson []byte) (*tfjson.Plan, error) {
+	var err error
+
+	if r.PlanJSONFormat != "" && r.PlanJSONFormat != PLAN_JSON_FORMAT_RAW {
+		planJson, err = unwrapPlanJSON(planJson, r.PlanJSONFormat)
+		if err != nil {
+			return nil, withKind(ErrPlanParse, fmt.Errorf("unable to read Plan (%s): %w", path, err))
+		}
+	}
+
+	planJson, err = completePartialPlanJSON(planJson)
+	if err != nil {
+		return nil, withKind(ErrPlanParse, fmt.Errorf("unable to read Plan (%s): %w", path, err))
+	}
+
+	plan := &tfjson.Plan{}
+	if err := json.Unmarshal(planJson, plan); err != nil {
+		return nil, withKind(ErrPlanParse, fmt.Errorf("unable to read Plan (%s): %w", path, err))
+	}
+	r.readPreviousAddresses(prefix, planJson)
+	r.readReplaceReasons(prefix, planJson)
+
+	if isPartialPlan(plan) {
+		if hasConfig(plan) {
+			logStatus(COLOR_YELLOW, "WARNING: %s has no planned values, they are derived from its resource changes", path)
+		} else {
+			logStatus(COLOR_YELLOW, "WARNING: %s only contains resource changes, the module hierarchy is derived from their addresses and references between resources are missing", path)
+		}
+		completePartialPlan(plan)
+	}
+
+	return plan, nil
+}
+
+// unwrapPlanJSON returns the plan nested in an envelope of the given format
+func unwrapPlanJSON(content []byte, format string) ([]byte, error) {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(content, &envelope); err != nil {
+		return nil, err
+	}
+
+	for _, keys := range planJSONEnvelopes[format] {
+		if plan, ok := lookupJSON(envelope, keys); ok {
+			return plan, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no plan found in %s envelope", format)
+}
+
+// lookupJSON returns the object at the path of keys if it looks like a plan
+func lookupJSON(object map[string]json.RawMessage, keys []string) (json.RawMessage, bool) {
+	for i, key := range keys {
+		value, ok := object[key]
+		if !ok {
+			return nil, false
+		}
+
+		object = nil
+		if err := json.Unmarshal(value, &object); err != nil || object == nil {
+			return nil, false
+		}
+
+		if i == len(keys)-1 {
+			_, isPlan := object["format_version"]
+			return value, isPlan
+		}
+	}
+
+	return nil, false
+}
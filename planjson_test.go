@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanModuleName(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: "network.json", want: "network"},
+		{file: "plans/app-prod.json", want: "app-prod"},
+		{file: "stack.plan.json", want: "stack_plan"},
+		{file: "my stack.json", want: "my_stack"},
+		{file: "2024-01-01.json", want: "_2024-01-01"},
+		{file: "-stack.json", want: "_-stack"},
+		{file: ".json", want: "_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := planModuleName(tt.file); got != tt.want {
+				t.Errorf("planModuleName(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetJSONPlansDuplicateNames(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"my stack.json", "my_stack.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := &rover{PlanJSONPath: dir}
+	err := r.getJSONPlans()
+	if err == nil || !strings.Contains(err.Error(), "module.my_stack") {
+		t.Errorf("getJSONPlans() error = %v, want an error about module.my_stack", err)
+	}
+}
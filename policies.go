@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// PolicyCheck is the result of a Sentinel or OPA policy check of a Terraform Cloud run
+type PolicyCheck struct {
+	ID        string `json:"id"`
+	Workspace string `json:"workspace"`
+	Status    string `json:"status"`
+	Passed    int    `json:"passed"`
+	// Failed is the number of advisory, soft and hard mandatory policies that failed
+	Failed int `json:"failed"`
+	// Resources are the addresses of the resources the output of a failed check mentions
+	Resources []string `json:"resources,omitempty"`
+}
+
+// failed returns true if policies of the check failed or the check couldn't be completed
+func (pc PolicyCheck) failed() bool {
+	switch tfe.PolicyStatus(pc.Status) {
+	case tfe.PolicyHardFailed, tfe.PolicySoftFailed, tfe.PolicyErrored:
+		return true
+	}
+	return pc.Failed > 0
+}
+
+// getTFCPolicyChecks returns the policy checks of the run. Policy checks don't report which
+// resources a policy failed for, so the resources of the plan mentioned in the output of failed
+// checks are attributed to them, with prefix added to their addresses.
+func getTFCPolicyChecks(client *tfe.Client, run *tfe.Run, workspaceName string, plan *tfjson.Plan, prefix string) ([]PolicyCheck, error) {
+	list, err := client.PolicyChecks.List(context.Background(), run.ID, &tfe.PolicyCheckListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list policy checks of run %s: %s", run.ID, err)
+	}
+
+	checks := []PolicyCheck{}
+	for _, pc := range list.Items {
+		check := PolicyCheck{
+			ID:        pc.ID,
+			Workspace: workspaceName,
+			Status:    string(pc.Status),
+		}
+		if pc.Result != nil {
+			check.Passed = pc.Result.Passed
+			check.Failed = pc.Result.TotalFailed
+		}
+
+		if check.failed() {
+			logs, err := client.PolicyChecks.Logs(context.Background(), pc.ID)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read output of policy check %s: %s", pc.ID, err)
+			}
+			output, err := io.ReadAll(logs)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read output of policy check %s: %s", pc.ID, err)
+			}
+
+			for _, rc := range plan.ResourceChanges {
+				if mentionsAddress(string(output), rc.Address) {
+					address := rc.Address
+					if prefix != "" {
+						address = prefixAddress(prefix, address)
+					}
+					check.Resources = append(check.Resources, address)
+				}
+			}
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+// mentionsAddress returns true if output contains address, and not only as part of a longer
+// address such as the same resource in a module or a resource with a longer name
+func mentionsAddress(output string, address string) bool {
+	isAddressChar := func(c byte) bool {
+		return c == '_' || c == '-' || c == '.' || c == '[' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+	}
+
+	for i := strings.Index(output, address); i >= 0; {
+		end := i + len(address)
+		// Attributes may follow the address
+		if (i == 0 || !isAddressChar(output[i-1])) && (end == len(output) || output[end] == '.' || !isAddressChar(output[end])) {
+			return true
+		}
+
+		next := strings.Index(output[i+1:], address)
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+
+	return false
+}
+
+// annotatePolicies marks the nodes of resources failing a policy check with the check's status
+func (r *rover) annotatePolicies(nodes []Node) {
+	failures := map[string]string{}
+	for _, pc := range r.Policies {
+		if !pc.failed() {
+			continue
+		}
+		for _, address := range pc.Resources {
+			// Hard failures outweigh soft ones
+			if failures[address] != string(tfe.PolicyHardFailed) {
+				failures[address] = pc.Status
+			}
+		}
+	}
+
+	for i, n := range nodes {
+		status, ok := failures[n.Data.ID]
+		if !ok {
+			continue
+		}
+
+		nodes[i].Data.PolicyResult = status
+		nodes[i].Classes = fmt.Sprintf("%s policy-failed", n.Classes)
+	}
+}
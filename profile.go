@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	runtimepprof "runtime/pprof"
+)
+
+const (
+	CPU_PROFILE_FILE  string = "rover-cpu.pprof"
+	HEAP_PROFILE_FILE string = "rover-heap.pprof"
+)
+
+// startCPUProfile starts writing a CPU profile, the returned function stops it
+func startCPUProfile(filename string) (func(), error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create CPU profile: %s", err)
+	}
+
+	if err := runtimepprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("unable to start CPU profile: %s", err)
+	}
+
+	return func() {
+		runtimepprof.StopCPUProfile()
+		f.Close()
+		log.Printf("Saved CPU profile to %s", filename)
+	}, nil
+}
+
+// writeHeapProfile writes the current heap profile to filename
+func writeHeapProfile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("unable to create heap profile: %s", err)
+	}
+	defer f.Close()
+
+	// Get up-to-date statistics
+	runtime.GC()
+	if err := runtimepprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("unable to write heap profile: %s", err)
+	}
+
+	log.Printf("Saved heap profile to %s", filename)
+	return nil
+}
+
+// registerPprof adds the net/http/pprof handlers under /debug/pprof/
+func registerPprof(m *http.ServeMux) {
+	m.HandleFunc("/debug/pprof/", pprof.Index)
+	m.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	m.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	m.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	m.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
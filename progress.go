@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+)
+
+// Phases of generateAssets broadcast once they complete
+const (
+	PROGRESS_INIT  string = "init"
+	PROGRESS_PLAN  string = "plan"
+	PROGRESS_RSO   string = "rso"
+	PROGRESS_MAP   string = "map"
+	PROGRESS_GRAPH string = "graph"
+	PROGRESS_DONE  string = "done"
+	PROGRESS_ERROR string = "error"
+)
+
+// PROGRESS_BUFFER is the number of updates queued for a slow client before further ones are dropped
+const PROGRESS_BUFFER = 16
+
+// Progress is a generation progress update
+type Progress struct {
+	Phase string `json:"phase"`
+	Error string `json:"error,omitempty"`
+}
+
+// progressHub broadcasts progress updates to the connected websocket clients
+type progressHub struct {
+	mu      sync.Mutex
+	clients map[chan []byte]bool
+	// last is sent to clients as they connect, so they don't wait for the next update
+	last []byte
+}
+
+func newProgressHub() *progressHub {
+	return &progressHub{clients: make(map[chan []byte]bool)}
+}
+
+func (h *progressHub) broadcast(p Progress) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		log.Printf("Error producing progress JSON: %s", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.last = b
+	for client := range h.clients {
+		select {
+		case client <- b:
+		default:
+		}
+	}
+}
+
+func (h *progressHub) subscribe() chan []byte {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client := make(chan []byte, PROGRESS_BUFFER)
+	if h.last != nil {
+		client <- h.last
+	}
+	h.clients[client] = true
+
+	return client
+}
+
+func (h *progressHub) unsubscribe(client chan []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients, client)
+}
+
+// reportProgress broadcasts that the phase of generating the assets completed
+func (r *rover) reportProgress(phase string) {
+	if r.progress == nil {
+		return
+	}
+	r.progress.broadcast(Progress{Phase: phase})
+}
+
+// reportProgressError broadcasts that generating the assets failed
+func (r *rover) reportProgressError(err error) {
+	if r.progress == nil {
+		return
+	}
+	r.progress.broadcast(Progress{Phase: PROGRESS_ERROR, Error: err.Error()})
+}
+
+// handleProgress streams the progress of generating the assets over a websocket
+func (ro *rover) handleProgress(w http.ResponseWriter, r *http.Request) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		http.Error(w, "Please connect with a websocket", http.StatusBadRequest)
+		return
+	}
+
+	conn, _, _, err := ws.UpgradeHTTP(r, w)
+	if err != nil {
+		log.Printf("Error upgrading to websocket: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	// The server's read and write timeouts would close the websocket while it's idle
+	conn.SetDeadline(time.Time{})
+
+	client := ro.progress.subscribe()
+	defer ro.progress.unsubscribe(client)
+
+	// Only control frames are expected from the client, reading stops once it disconnects
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := wsutil.ReadClientData(conn); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case b := <-client:
+			if err := wsutil.WriteServerText(conn, b); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// RATE_LIMIT_MIN_BURST lets a browser load the frontend and its assets at once
+	RATE_LIMIT_MIN_BURST int = 20
+	// RATE_LIMIT_IDLE is how long the limiter of a client is kept after its last request
+	RATE_LIMIT_IDLE = 10 * time.Minute
+)
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimitHandler limits every client IP to --rateLimit requests per second with a token bucket,
+// responding with 429 Too Many Requests once it is empty. The healthcheck isn't limited.
+func (ro *rover) rateLimitHandler(h http.Handler) http.Handler {
+	if ro.RateLimit <= 0 {
+		return h
+	}
+
+	burst := int(math.Ceil(ro.RateLimit))
+	if burst < RATE_LIMIT_MIN_BURST {
+		burst = RATE_LIMIT_MIN_BURST
+	}
+
+	var mu sync.Mutex
+	clients := map[string]*clientLimiter{}
+	lastPruned := time.Now()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+
+		now := time.Now()
+
+		mu.Lock()
+		if now.Sub(lastPruned) > RATE_LIMIT_IDLE {
+			for client, c := range clients {
+				if now.Sub(c.lastSeen) > RATE_LIMIT_IDLE {
+					delete(clients, client)
+				}
+			}
+			lastPruned = now
+		}
+		c, ok := clients[ip]
+		if !ok {
+			c = &clientLimiter{limiter: rate.NewLimiter(rate.Limit(ro.RateLimit), burst)}
+			clients[ip] = c
+		}
+		c.lastSeen = now
+		reservation := c.limiter.ReserveN(now, 1)
+		mu.Unlock()
+
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(delay.Seconds()))))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/hashicorp/terraform-json/sanitize"
+)
+
+const (
+	SANITIZE_MODE_FULL           string = "full"
+	SANITIZE_MODE_PRESERVE_SHAPE string = "redact-preserve-shape"
+)
+
+// redactedSentinel marks sensitive values until they are replaced by placeholders of the same shape
+const redactedSentinel = "\x00rover-redacted\x00"
+
+// sanitizePlan masks sensitive values according to r.SanitizeMode
+func (r *rover) sanitizePlan(plan *tfjson.Plan) (*tfjson.Plan, error) {
+	if r.SanitizeMode != SANITIZE_MODE_PRESERVE_SHAPE {
+		return sanitize.SanitizePlan(plan)
+	}
+
+	sanitized, err := sanitize.SanitizePlanWithValue(plan, redactedSentinel)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sanitized and original plans only differ where the sentinel replaced a sensitive value,
+	// so walking both in parallel finds the original value of every sentinel
+	var sanitizedValues, originalValues interface{}
+	if err := roundTrip(sanitized, &sanitizedValues); err != nil {
+		return nil, err
+	}
+	if err := roundTrip(plan, &originalValues); err != nil {
+		return nil, err
+	}
+
+	result := &tfjson.Plan{}
+	if err := roundTrip(restoreShape(sanitizedValues, originalValues), result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// roundTrip converts from into to through JSON
+func roundTrip(from interface{}, to interface{}) error {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return fmt.Errorf("error producing JSON: %s", err)
+	}
+	return json.Unmarshal(b, to)
+}
+
+// restoreShape replaces every sentinel in sanitized with a placeholder shaped like the original value
+func restoreShape(sanitized interface{}, original interface{}) interface{} {
+	switch s := sanitized.(type) {
+	case string:
+		if s == redactedSentinel {
+			return shapeOf(original)
+		}
+	case map[string]interface{}:
+		o, _ := original.(map[string]interface{})
+		for k, v := range s {
+			s[k] = restoreShape(v, o[k])
+		}
+	case []interface{}:
+		o, _ := original.([]interface{})
+		for i, v := range s {
+			var ov interface{}
+			if i < len(o) {
+				ov = o[i]
+			}
+			s[i] = restoreShape(v, ov)
+		}
+	}
+
+	return sanitized
+}
+
+// shapeOf returns a placeholder with the same type and length as v
+func shapeOf(v interface{}) interface{} {
+	switch x := v.(type) {
+	case string:
+		return strings.Repeat("*", len([]rune(x)))
+	case float64:
+		return 0
+	case bool:
+		return false
+	case map[string]interface{}:
+		shaped := make(map[string]interface{})
+		for k, e := range x {
+			shaped[k] = shapeOf(e)
+		}
+		return shaped
+	case []interface{}:
+		shaped := make([]interface{}, len(x))
+		for i, e := range x {
+			shaped[i] = shapeOf(e)
+		}
+		return shaped
+	}
+
+	return v
+}
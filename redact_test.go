@@ -0,0 +1,212 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// sameShape returns true if a and b have the same types, string lengths and collection sizes
+func sameShape(a, b interface{}) bool {
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return ok && len([]rune(x)) == len([]rune(y))
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if !sameShape(v, y[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !sameShape(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.TypeOf(a) == reflect.TypeOf(b)
+}
+
+func TestShapeOf(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{
+			name:  "string",
+			value: "hunter2",
+			want:  "*******",
+		},
+		{
+			name:  "multibyte string keeps its length in characters",
+			value: "pässwörd",
+			want:  "********",
+		},
+		{
+			name:  "number",
+			value: 8080.0,
+			want:  0,
+		},
+		{
+			name:  "bool",
+			value: true,
+			want:  false,
+		},
+		{
+			name:  "null",
+			value: nil,
+			want:  nil,
+		},
+		{
+			name: "nested map",
+			value: map[string]interface{}{
+				"user": "admin",
+				"port": 5432.0,
+				"tls":  map[string]interface{}{"enabled": true, "ca": "pem"},
+			},
+			want: map[string]interface{}{
+				"user": "*****",
+				"port": 0,
+				"tls":  map[string]interface{}{"enabled": false, "ca": "***"},
+			},
+		},
+		{
+			name:  "list",
+			value: []interface{}{"a", "bc", []interface{}{1.0, "def"}},
+			want:  []interface{}{"*", "**", []interface{}{0, "***"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shapeOf(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shapeOf() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestoreShape(t *testing.T) {
+	tests := []struct {
+		name      string
+		sanitized interface{}
+		original  interface{}
+		want      interface{}
+	}{
+		{
+			name:      "sensitive value",
+			sanitized: redactedSentinel,
+			original:  "secret",
+			want:      "******",
+		},
+		{
+			name:      "value without sentinel is kept",
+			sanitized: "public",
+			original:  "public",
+			want:      "public",
+		},
+		{
+			name: "sensitive values in nested maps",
+			sanitized: map[string]interface{}{
+				"name": "db",
+				"auth": map[string]interface{}{"password": redactedSentinel, "user": "admin"},
+			},
+			original: map[string]interface{}{
+				"name": "db",
+				"auth": map[string]interface{}{"password": "hunter2", "user": "admin"},
+			},
+			want: map[string]interface{}{
+				"name": "db",
+				"auth": map[string]interface{}{"password": "*******", "user": "admin"},
+			},
+		},
+		{
+			name:      "sensitive list elements",
+			sanitized: []interface{}{"public", redactedSentinel, redactedSentinel},
+			original:  []interface{}{"public", "key", 42.0},
+			want:      []interface{}{"public", "***", 0},
+		},
+		{
+			name:      "whole sensitive block",
+			sanitized: map[string]interface{}{"block": redactedSentinel},
+			original:  map[string]interface{}{"block": []interface{}{map[string]interface{}{"token": "abcd"}}},
+			want:      map[string]interface{}{"block": []interface{}{map[string]interface{}{"token": "****"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := restoreShape(tt.sanitized, tt.original); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("restoreShape() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizePlanPreserveShape(t *testing.T) {
+	after := map[string]interface{}{
+		"name":     "db",
+		"password": "hunter2",
+		"ports":    []interface{}{5432.0, 5433.0},
+		"settings": map[string]interface{}{"token": "abcd", "enabled": true},
+	}
+	plan := &tfjson.Plan{
+		FormatVersion: "1.0",
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+		Config:        &tfjson.Config{RootModule: &tfjson.ConfigModule{}},
+		ResourceChanges: []*tfjson.ResourceChange{
+			{
+				Address: "aws_db_instance.db",
+				Type:    "aws_db_instance",
+				Name:    "db",
+				Change: &tfjson.Change{
+					Actions: tfjson.Actions{tfjson.ActionCreate},
+					After:   after,
+					AfterSensitive: map[string]interface{}{
+						"password": true,
+						"ports":    []interface{}{false, true},
+						"settings": true,
+					},
+				},
+			},
+		},
+	}
+
+	r := &rover{SanitizeMode: SANITIZE_MODE_PRESERVE_SHAPE}
+	sanitized, err := r.sanitizePlan(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := sanitized.ResourceChanges[0].Change.After.(map[string]interface{})
+	if !sameShape(got, after) {
+		t.Errorf("sanitized values %#v don't have the shape of %#v", got, after)
+	}
+
+	want := map[string]interface{}{
+		"name":     "db",
+		"password": "*******",
+		"ports":    []interface{}{5432.0, 0.0},
+		"settings": map[string]interface{}{"token": "****", "enabled": false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sanitized values = %#v, want %#v", got, want)
+	}
+	if after["password"] != "hunter2" {
+		t.Error("sanitizing modified the original plan")
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+)
+
+// refresh regenerates the assets and swaps them in at once, so in-flight requests
+// keep serving the previous assets until the new ones are complete
+func (r *rover) refresh() error {
+	return r.refreshAssets(nil)
+}
+
+// refreshAssets regenerates the assets like refresh, from the uploaded plan JSON if set
+func (r *rover) refreshAssets(uploadedPlan []byte) error {
+	log.Println("Refreshing assets...")
+
+	// Only the copy is written to while generating, its locks are shared with r.
+	// Its assets start empty so the ones being served are never decoded into.
+	next := *r
+	next.uploadedPlan = uploadedPlan
+	next.Plan = nil
+	next.RSO = nil
+	next.Map = nil
+	next.Graph = Graph{}
+	next.Diagnostics = []Diagnostic{}
+	next.History = nil
+	next.Policies = nil
+	// Read again while getting the plan. Apply results belong to the Terraform Cloud run,
+	// not to an uploaded plan.
+	next.PreviousAddresses = nil
+	next.ReplaceReasons = nil
+	if uploadedPlan != nil {
+		next.ApplyResults = nil
+	}
+	if err := next.generateAssets(); err != nil {
+		return err
+	}
+
+	r.assetsMu.Lock()
+	r.Plan = next.Plan
+	r.RSO = next.RSO
+	r.Map = next.Map
+	r.Graph = next.Graph
+	r.Diagnostics = next.Diagnostics
+	r.History = next.History
+	r.Policies = next.Policies
+	r.ApplyResults = next.ApplyResults
+	r.PreviousAddresses = next.PreviousAddresses
+	r.ReplaceReasons = next.ReplaceReasons
+	r.GeneratedAt = next.GeneratedAt
+	r.TfVersion = next.TfVersion
+	r.assetsMu.Unlock()
+	r.reportProgress(PROGRESS_DONE)
+
+	log.Println("Done refreshing assets.")
+
+	return nil
+}
+
+// snapshot returns a copy of the rover holding a consistent set of assets. The copy
+// can be read without holding the lock because refresh replaces assets instead of
+// modifying them.
+func (r *rover) snapshot() *rover {
+	r.assetsMu.RLock()
+	defer r.assetsMu.RUnlock()
+
+	s := *r
+	return &s
+}
+
+// handleRefresh regenerates the assets on POST and responds with the new summary. It's only
+// served with --allowRefresh, and without CORS headers so other sites can't trigger plans.
+func (ro *rover) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Refresh must be requested with POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !ro.refreshMu.TryLock() {
+		http.Error(w, "Refresh already in progress", http.StatusConflict)
+		return
+	}
+	defer ro.refreshMu.Unlock()
+
+	if err := ro.refresh(); err != nil {
+		http.Error(w, fmt.Sprintf("Error refreshing assets: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	j, err := json.Marshal(ro.snapshot().summary())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error producing summary JSON: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, string(j))
+}
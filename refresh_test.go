@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestHandleRefresh(t *testing.T) {
+	r := testRover(t, "plan.json")
+	if err := r.generateAssets(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r.handleRefresh(w, httptest.NewRequest(http.MethodGet, "/api/refresh", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: status %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	w = httptest.NewRecorder()
+	r.handleRefresh(w, httptest.NewRequest(http.MethodPost, "/api/refresh", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST: status %d: %s", w.Code, w.Body.String())
+	}
+	var summary Summary
+	if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Total != 4 || summary.Create != 4 {
+		t.Errorf("summary = %+v, want 4 resources to create", summary)
+	}
+
+	// A refresh is already running while the lock is held
+	r.refreshMu.Lock()
+	w = httptest.NewRecorder()
+	r.handleRefresh(w, httptest.NewRequest(http.MethodPost, "/api/refresh", nil))
+	r.refreshMu.Unlock()
+	if w.Code != http.StatusConflict {
+		t.Errorf("concurrent POST: status %d, want %d", w.Code, http.StatusConflict)
+	}
+}
+
+// TestRefreshConcurrentReads reads the assets while refreshes swap them, for go test -race
+func TestRefreshConcurrentReads(t *testing.T) {
+	r := testRover(t, "plan.json")
+	if err := r.generateAssets(); err != nil {
+		t.Fatal(err)
+	}
+
+	planJson, err := os.ReadFile(r.PlanJSONPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := http.NewServeMux()
+	r.registerAssets(m, "", nil)
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, path := range []string{"/api/plan", "/api/rso", "/api/map", "/api/graph", "/api/diagnostics"} {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				resp, err := http.Get(srv.URL + path)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					t.Errorf("GET %s: status %d", path, resp.StatusCode)
+					return
+				}
+			}
+		}(path)
+	}
+
+	for i := 0; i < 5; i++ {
+		if err := r.refresh(); err != nil {
+			t.Error(err)
+		}
+		if err := r.refreshAssets(planJson); err != nil {
+			t.Error(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if len(r.snapshot().Plan.ResourceChanges) != 4 {
+		t.Errorf("got %d resource changes after refreshing, want 4", len(r.snapshot().Plan.ResourceChanges))
+	}
+}
+
+func TestRefreshRequiresAllowRefresh(t *testing.T) {
+	for _, allowRefresh := range []bool{false, true} {
+		r := testRover(t, "plan.json")
+		r.AllowRefresh = allowRefresh
+		if err := r.generateAssets(); err != nil {
+			t.Fatal(err)
+		}
+		generatedAt := r.snapshot().GeneratedAt
+
+		m := http.NewServeMux()
+		r.registerAssets(m, "", nil)
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/refresh", nil))
+
+		if allowRefresh {
+			if w.Code != http.StatusOK {
+				t.Errorf("status %d: %s", w.Code, w.Body.String())
+			}
+			if origin := w.Header().Get("Access-Control-Allow-Origin"); origin != "" {
+				t.Errorf("Access-Control-Allow-Origin is %q, want none", origin)
+			}
+		}
+		if refreshed := !r.snapshot().GeneratedAt.Equal(generatedAt); refreshed != allowRefresh {
+			t.Errorf("allowRefresh=%t: refreshed is %t", allowRefresh, refreshed)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// relativizePaths makes the filesystem paths of the RSO's module locations and configuration
+// relative to the working directory, so shared exports don't reveal the directory layout of
+// whoever generated them. The map takes its path from the root module's configuration, so
+// this must run before GenerateMap.
+func (r *rover) relativizePaths() {
+	base, err := filepath.Abs(r.WorkingDir)
+	if err != nil {
+		log.Printf("Unable to make paths relative: %s", err)
+		return
+	}
+
+	for key, location := range r.RSO.Locations {
+		r.RSO.Locations[key] = relativePath(base, location)
+	}
+
+	// Instances of a module share its configuration, so each is only rewritten once
+	seen := map[*tfconfig.Module]bool{}
+	for _, config := range r.RSO.Configs {
+		if config.Module == nil || seen[config.Module] {
+			continue
+		}
+		seen[config.Module] = true
+
+		relativizeModulePaths(base, config.Module)
+	}
+}
+
+func relativizeModulePaths(base string, module *tfconfig.Module) {
+	module.Path = relativePath(base, module.Path)
+
+	for _, v := range module.Variables {
+		v.Pos.Filename = relativePath(base, v.Pos.Filename)
+	}
+	for _, o := range module.Outputs {
+		o.Pos.Filename = relativePath(base, o.Pos.Filename)
+	}
+	for _, re := range module.ManagedResources {
+		re.Pos.Filename = relativePath(base, re.Pos.Filename)
+	}
+	for _, re := range module.DataResources {
+		re.Pos.Filename = relativePath(base, re.Pos.Filename)
+	}
+	for _, mc := range module.ModuleCalls {
+		mc.Pos.Filename = relativePath(base, mc.Pos.Filename)
+	}
+	for _, d := range module.Diagnostics {
+		if d.Pos != nil {
+			d.Pos.Filename = relativePath(base, d.Pos.Filename)
+		}
+	}
+}
+
+// relativePath returns path relative to the absolute base directory. Paths outside of base
+// keep only their file name, rather than climbing out of base with "..".
+func relativePath(base string, path string) string {
+	if path == "" {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+
+	rel, err := filepath.Rel(base, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Base(path)
+	}
+
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// planReplaceReasons holds why the plan's resource changes replace resources, which
+// terraform-json doesn't decode
+type planReplaceReasons struct {
+	ResourceChanges []struct {
+		Address      string `json:"address"`
+		ActionReason string `json:"action_reason"`
+		Change       struct {
+			ReplacePaths [][]interface{} `json:"replace_paths"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// replaceReason describes why Terraform replaces a resource, from the action_reason and
+// replace_paths of its change
+func replaceReason(actionReason string, replacePaths [][]interface{}) string {
+	switch actionReason {
+	case "replace_because_tainted":
+		return "tainted"
+	case "replace_by_request":
+		return "requested with -replace"
+	case "replace_by_triggers":
+		return "triggered by replace_triggered_by"
+	}
+
+	paths := []string{}
+	for _, path := range replacePaths {
+		var b strings.Builder
+		for _, step := range path {
+			switch s := step.(type) {
+			case string:
+				if b.Len() > 0 {
+					b.WriteString(".")
+				}
+				b.WriteString(s)
+			case float64:
+				fmt.Fprintf(&b, "[%d]", int(s))
+			}
+		}
+		paths = append(paths, b.String())
+	}
+	if len(paths) > 0 {
+		return fmt.Sprintf("%s forces replacement", strings.Join(paths, ", "))
+	}
+
+	return strings.ReplaceAll(strings.TrimPrefix(actionReason, "replace_"), "_", " ")
+}
+
+// readReplaceReasons records why replaced resources are replaced from the plan JSON.
+// Addresses are prefixed with prefix, if set, to match merged plans.
+func (r *rover) readReplaceReasons(prefix string, planJson []byte) {
+	var p planReplaceReasons
+	if err := json.Unmarshal(planJson, &p); err != nil {
+		// The plan itself failed or will fail to parse, which is reported instead
+		return
+	}
+
+	if r.ReplaceReasons == nil {
+		r.ReplaceReasons = map[string]string{}
+	}
+
+	for _, rc := range p.ResourceChanges {
+		// Other action reasons explain deletions and reads
+		if !strings.HasPrefix(rc.ActionReason, "replace_") && len(rc.Change.ReplacePaths) == 0 {
+			continue
+		}
+		reason := replaceReason(rc.ActionReason, rc.Change.ReplacePaths)
+
+		address := rc.Address
+		if prefix != "" {
+			address = prefixAddress(prefix, address)
+		}
+		r.ReplaceReasons[address] = reason
+	}
+}
+
+// annotateReplaceReasons adds why replaced resources are replaced to their nodes. Terraform plans a
+// replacement as a single change with both delete and create actions, in either order, so it's
+// drawn as a single replace node. Collapsed resources get the reason of their first replaced instance.
+func (r *rover) annotateReplaceReasons(nodes []Node) {
+	if r.Plan == nil || len(r.ReplaceReasons) == 0 {
+		return
+	}
+
+	// Reasons of the replaced resource instances, and of the resources collapsed instances belong to
+	reasons := map[string]string{}
+	resourceReasons := map[string]string{}
+	addresses := []string{}
+	for _, rc := range r.Plan.ResourceChanges {
+		if rc.Change != nil && rc.Change.Actions.Replace() && r.ReplaceReasons[rc.Address] != "" {
+			addresses = append(addresses, rc.Address)
+		}
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		reasons[address] = r.ReplaceReasons[address]
+		if loc := instanceKeyPattern.FindStringIndex(address); loc != nil {
+			if _, ok := resourceReasons[address[:loc[0]]]; !ok {
+				resourceReasons[address[:loc[0]]] = r.ReplaceReasons[address]
+			}
+		}
+	}
+
+	for i, n := range nodes {
+		reason, ok := reasons[n.Data.ID]
+		if !ok && n.Data.Instances > 0 {
+			reason, ok = resourceReasons[n.Data.ID]
+		}
+		if !ok {
+			continue
+		}
+
+		nodes[i].Data.ReplaceReason = reason
+	}
+}
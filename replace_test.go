@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReplaceReason(t *testing.T) {
+	tests := []struct {
+		name         string
+		actionReason string
+		replacePaths [][]interface{}
+		want         string
+	}{
+		{
+			name:         "tainted",
+			actionReason: "replace_because_tainted",
+			want:         "tainted",
+		},
+		{
+			name:         "-replace",
+			actionReason: "replace_by_request",
+			want:         "requested with -replace",
+		},
+		{
+			name:         "replace_triggered_by",
+			actionReason: "replace_by_triggers",
+			want:         "triggered by replace_triggered_by",
+		},
+		{
+			name:         "replace_paths",
+			actionReason: "replace_because_cannot_update",
+			replacePaths: [][]interface{}{{"ami"}, {"tags", "Name"}, {"ports", float64(0), "to"}},
+			want:         "ami, tags.Name, ports[0].to forces replacement",
+		},
+		{
+			name:         "replace_paths without action reason",
+			replacePaths: [][]interface{}{{"ami"}},
+			want:         "ami forces replacement",
+		},
+		{
+			name:         "unknown action reason",
+			actionReason: "replace_because_of_something_new",
+			want:         "because of something new",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replaceReason(tt.actionReason, tt.replacePaths); got != tt.want {
+				t.Errorf("replaceReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotateReplaceReasons(t *testing.T) {
+	tests := []struct {
+		name              string
+		collapseInstances bool
+		want              map[string]string
+	}{
+		{
+			name: "instances",
+			want: map[string]string{
+				"null_resource.a":    "triggers, tags.Name, ports[0] forces replacement",
+				"null_resource.b[0]": "requested with -replace",
+				"null_resource.b[1]": "tainted",
+				"null_resource.c":    "triggered by replace_triggered_by",
+			},
+		},
+		{
+			name:              "collapsed instances get the reason of the first instance",
+			collapseInstances: true,
+			want: map[string]string{
+				"null_resource.a": "triggers, tags.Name, ports[0] forces replacement",
+				"null_resource.b": "requested with -replace",
+				"null_resource.c": "triggered by replace_triggered_by",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testRover(t, "replace.json")
+			r.CollapseInstances = tt.collapseInstances
+			if err := r.generateAssets(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]string{}
+			for _, n := range r.Graph.Nodes {
+				if n.Data.Type != ResourceTypeResource || n.Data.Change == "" {
+					continue
+				}
+				got[n.Data.ID] = n.Data.ReplaceReason
+
+				// Delete and create actions in either order make a single replace node
+				if n.Data.Change != string(ActionReplace) {
+					t.Errorf("node %s has change %q, want %q", n.Data.ID, n.Data.Change, ActionReplace)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("replace reasons of graph nodes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
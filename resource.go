@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// ResourceDiff is the planned change of a single resource
+type ResourceDiff struct {
+	Address      string         `json:"address"`
+	Actions      tfjson.Actions `json:"actions"`
+	Before       interface{}    `json:"before"`
+	After        interface{}    `json:"after"`
+	AfterUnknown interface{}    `json:"after_unknown,omitempty"`
+	// Changed lists the top level attributes that change, including those only known after apply
+	Changed []string `json:"changed"`
+	// Unknown lists the paths of the attributes only known after apply, with --highlightUnknown
+	Unknown []string `json:"unknown,omitempty"`
+}
+
+// resourceDiff returns the planned change of the resource with the given address.
+// The plan is already sanitized unless --showSensitive is set.
+func (r *rover) resourceDiff(address string) (ResourceDiff, bool) {
+	if r.Plan == nil {
+		return ResourceDiff{}, false
+	}
+
+	for _, rc := range r.Plan.ResourceChanges {
+		if rc.Address != address || rc.Change == nil {
+			continue
+		}
+
+		diff := ResourceDiff{
+			Address:      rc.Address,
+			Actions:      rc.Change.Actions,
+			Before:       rc.Change.Before,
+			After:        rc.Change.After,
+			AfterUnknown: rc.Change.AfterUnknown,
+			Changed:      changedAttributes(rc.Change),
+		}
+		if r.HighlightUnknown {
+			diff.Unknown = unknownAttributes(rc.Change)
+		}
+
+		return diff, true
+	}
+
+	return ResourceDiff{}, false
+}
+
+// changedAttributes returns the sorted names of the top level attributes differing between
+// before and after, or unknown until after apply
+func changedAttributes(change *tfjson.Change) []string {
+	before, _ := change.Before.(map[string]interface{})
+	after, _ := change.After.(map[string]interface{})
+	unknown, _ := change.AfterUnknown.(map[string]interface{})
+
+	changed := map[string]bool{}
+	for name, v := range before {
+		if !reflect.DeepEqual(v, after[name]) {
+			changed[name] = true
+		}
+	}
+	for name, v := range after {
+		if !reflect.DeepEqual(v, before[name]) {
+			changed[name] = true
+		}
+	}
+	for name, v := range unknown {
+		// Objects and lists mirror the nesting of after, so only count them if they mark a value unknown
+		var paths []string
+		collectUnknown(name, v, &paths)
+		if len(paths) > 0 {
+			changed[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// handleResourceDiff responds with the planned change of the resource in the address query parameter
+func (ro *rover) handleResourceDiff(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		http.Error(w, "Please specify a resource address", http.StatusBadRequest)
+		return
+	}
+
+	diff, ok := ro.snapshot().resourceDiff(address)
+	if !ok {
+		http.Error(w, fmt.Sprintf("%s not found in plan", address), http.StatusNotFound)
+		return
+	}
+
+	j, err := json.Marshal(diff)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error producing resource JSON: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, string(j))
+}
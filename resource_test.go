@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestChangedAttributes(t *testing.T) {
+	cases := []struct {
+		name   string
+		change *tfjson.Change
+		want   []string
+	}{
+		{
+			name: "changed values",
+			change: &tfjson.Change{
+				Before: map[string]interface{}{"ami": "ami-1", "name": "web", "old": true},
+				After:  map[string]interface{}{"ami": "ami-2", "name": "web", "new": true},
+			},
+			want: []string{"ami", "new", "old"},
+		},
+		{
+			name: "unknown value",
+			change: &tfjson.Change{
+				Before:       map[string]interface{}{"name": "web"},
+				After:        map[string]interface{}{"name": "web"},
+				AfterUnknown: map[string]interface{}{"id": true, "name": false},
+			},
+			want: []string{"id"},
+		},
+		{
+			name: "known nested block",
+			change: &tfjson.Change{
+				Before:       map[string]interface{}{"block": []interface{}{map[string]interface{}{"size": 10.0}}},
+				After:        map[string]interface{}{"block": []interface{}{map[string]interface{}{"size": 10.0}}},
+				AfterUnknown: map[string]interface{}{"block": []interface{}{map[string]interface{}{}}},
+			},
+			want: []string{},
+		},
+		{
+			name: "unknown value in nested block",
+			change: &tfjson.Change{
+				Before:       map[string]interface{}{"block": []interface{}{map[string]interface{}{"size": 10.0}}},
+				After:        map[string]interface{}{"block": []interface{}{map[string]interface{}{"size": 10.0}}},
+				AfterUnknown: map[string]interface{}{"block": []interface{}{map[string]interface{}{"arn": true}}},
+			},
+			want: []string{"block"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := changedAttributes(c.change)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("changedAttributes() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const (
+	DEFAULT_PAGE_SIZE int = 100
+	MAX_PAGE_SIZE     int = 1000
+)
+
+// RESOURCE_SORTS are the fields /api/resources can sort by
+var RESOURCE_SORTS = []string{"address", "action", "type"}
+
+// ResourceSummary is a resource change in the /api/resources list
+type ResourceSummary struct {
+	Address string `json:"address"`
+	Type    string `json:"type"`
+	Action  Action `json:"action"`
+}
+
+// ResourcePage is a page of the resource changes in the plan
+type ResourcePage struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	// Total is the number of resource changes on all pages
+	Total     int               `json:"total"`
+	Resources []ResourceSummary `json:"resources"`
+}
+
+// resourcePage returns the given page of the resource changes sorted by field, falling back to
+// the address for resources with the same action or type. Pages start at 1.
+func (r *rover) resourcePage(page int, pageSize int, field string) ResourcePage {
+	resources := []ResourceSummary{}
+	if r.Plan != nil {
+		for _, rc := range r.Plan.ResourceChanges {
+			action := ActionNoop
+			if rc.Change != nil {
+				action = changeAction(rc.Change.Actions)
+			}
+			resources = append(resources, ResourceSummary{
+				Address: rc.Address,
+				Type:    rc.Type,
+				Action:  action,
+			})
+		}
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		var a, b string
+		switch field {
+		case "action":
+			a, b = string(resources[i].Action), string(resources[j].Action)
+		case "type":
+			a, b = resources[i].Type, resources[j].Type
+		}
+		if a != b {
+			return a < b
+		}
+		return resources[i].Address < resources[j].Address
+	})
+
+	start := (page - 1) * pageSize
+	if start > len(resources) {
+		start = len(resources)
+	}
+	end := start + pageSize
+	if end > len(resources) {
+		end = len(resources)
+	}
+
+	return ResourcePage{
+		Page:      page,
+		PageSize:  pageSize,
+		Total:     len(resources),
+		Resources: resources[start:end],
+	}
+}
+
+// handleResources responds with a page of the resource changes, selected with the page, pageSize
+// and sort query parameters, so large plans don't have to be sent at once
+func (ro *rover) handleResources(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+
+	query := r.URL.Query()
+
+	page := 1
+	if p := query.Get("page"); p != "" {
+		var err error
+		page, err = strconv.Atoi(p)
+		if err != nil || page < 1 {
+			http.Error(w, fmt.Sprintf("Invalid page %q: must be a positive integer", p), http.StatusBadRequest)
+			return
+		}
+	}
+
+	pageSize := DEFAULT_PAGE_SIZE
+	if s := query.Get("pageSize"); s != "" {
+		var err error
+		pageSize, err = strconv.Atoi(s)
+		if err != nil || pageSize < 1 || pageSize > MAX_PAGE_SIZE {
+			http.Error(w, fmt.Sprintf("Invalid pageSize %q: must be between 1 and %d", s, MAX_PAGE_SIZE), http.StatusBadRequest)
+			return
+		}
+	}
+
+	field := query.Get("sort")
+	if field == "" {
+		field = "address"
+	}
+	valid := false
+	for _, s := range RESOURCE_SORTS {
+		valid = valid || s == field
+	}
+	if !valid {
+		http.Error(w, fmt.Sprintf("Invalid sort %q: must be one of address, action, type", field), http.StatusBadRequest)
+		return
+	}
+
+	snap := ro.snapshot()
+
+	// Pages only change once the assets are generated again
+	if notModified(w, r, fmt.Sprintf(`W/"%d"`, snap.GeneratedAt.UnixNano()), snap.GeneratedAt) {
+		return
+	}
+
+	j, err := json.Marshal(snap.resourcePage(page, pageSize, field))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error producing resources JSON: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, string(j))
+}
@@ -19,6 +19,20 @@ type ResourcesOverview struct {
 	Locations map[string]string          `json:"locations,omitempty"`
 	States    map[string]*StateOverview  `json:"states,omitempty"`
 	Configs   map[string]*ConfigOverview `json:"configs,omitempty"`
+	// Providers counts the resource changes of each provider by action
+	Providers map[string]*Summary `json:"providers,omitempty"`
+	// Imports lists the addresses of the resources the plan imports
+	Imports []string `json:"imports,omitempty"`
+	// Moves maps the addresses of the resources moved by moved blocks to their previous address
+	Moves map[string]string `json:"moves,omitempty"`
+	// Cost is the Infracost cost breakdown of the plan, with --infracostPath
+	Cost json.RawMessage `json:"cost,omitempty"`
+	// Drifts lists the addresses of the resources that changed outside of Terraform
+	Drifts []string `json:"drifts,omitempty"`
+	// OutputsOnly is set if the plan changes outputs without changing any resources
+	OutputsOnly bool `json:"outputs_only,omitempty"`
+	// Truncated is set if modules nested deeper than --maxModuleDepth were left out
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // ResourceOverview is a modified tfjson.Plan
@@ -30,6 +44,10 @@ type StateOverview struct {
 	Children  map[string]*StateOverview `json:"children,omitempty"`
 	Type      ResourceType              `json:"type,omitempty"`
 	IsParent  bool                      `json:"isparent,omitempty"`
+	// MovedFrom is the previous address of a resource moved without other changes
+	MovedFrom string `json:"moved_from,omitempty"`
+	// Drift is set if the resource changed outside of Terraform, as reported by refresh-only plans
+	Drift bool `json:"drift,omitempty"`
 }
 
 type ConfigOverview struct {
@@ -276,19 +294,25 @@ func (r *rover) GenerateResourceOverview() error {
 	rc := rso.Configs
 	rs := rso.States
 
-	// This is the location of modules.json, which contains where modules are stored on the local filesystem
-	moduleJSONPath := filepath.Join(r.WorkingDir, ".terraform/modules/modules.json")
-	r.PopulateModuleLocations(moduleJSONPath, rso.Locations)
-
 	// Create root module configuration
 	rc[""] = &ConfigOverview{}
-	rootModule, _ := tfconfig.LoadModule(r.WorkingDir)
-	// If module can be loaded from filesystem
-	if !rootModule.Diagnostics.HasErrors() {
-		rc[""].Module = rootModule
+
+	// Configuration files contain the original names, so they can't be used once anonymized
+	if r.Anonymize {
+		log.Printf("Continuing without configuration file data since plan is anonymized...")
 	} else {
-		log.Printf("Could not load configuration from: %v\n", r.WorkingDir)
-		log.Printf("Continuing without configuration file data...")
+		// This is the location of modules.json, which contains where modules are stored on the local filesystem
+		moduleJSONPath := filepath.Join(r.WorkingDir, ".terraform/modules/modules.json")
+		r.PopulateModuleLocations(moduleJSONPath, rso.Locations)
+
+		rootModule, _ := tfconfig.LoadModule(r.WorkingDir)
+		// If module can be loaded from filesystem
+		if !rootModule.Diagnostics.HasErrors() {
+			rc[""].Module = rootModule
+		} else {
+			log.Printf("Could not load configuration from: %v\n", r.WorkingDir)
+			log.Printf("Continuing without configuration file data...")
+		}
 	}
 
 	rc[""].ModuleConfig = &tfjson.ModuleCall{}
@@ -334,6 +358,15 @@ func (r *rover) GenerateResourceOverview() error {
 		rs[outputName].Type = ResourceTypeOutput
 	}
 
+	if r.outputsOnly() {
+		log.Println("Plan only changes outputs, no resources change")
+		rso.OutputsOnly = true
+	}
+
+	if r.showOutputs() {
+		r.addOutputStates(rs)
+	}
+
 	// Loop through resource changes
 	for _, resource := range r.Plan.ResourceChanges {
 		id := resource.Address
@@ -361,6 +394,23 @@ func (r *rover) GenerateResourceOverview() error {
 			}
 			rs[id].Change = *resource.Change
 
+			if resource.Change.Importing != nil {
+				rso.Imports = append(rso.Imports, id)
+			}
+
+			if isDrift(resource.Change) {
+				rs[id].Drift = true
+				rso.Drifts = append(rso.Drifts, id)
+			}
+
+			if previousAddress := r.movedFrom(resource); previousAddress != "" {
+				rs[id].MovedFrom = previousAddress
+				if rso.Moves == nil {
+					rso.Moves = map[string]string{}
+				}
+				rso.Moves[id] = previousAddress
+			}
+
 			// Create resource config if doesn't exist
 			if _, ok := rc[configId]; !ok {
 				rc[configId] = &ConfigOverview{}
@@ -375,6 +425,13 @@ func (r *rover) GenerateResourceOverview() error {
 		}
 	}
 
+	rso.Providers = r.providerSummaries()
+	rso.Truncated = r.ModulesTruncated
+
+	if err := r.FocusResourceOverview(rso); err != nil {
+		return err
+	}
+
 	r.RSO = rso
 
 	return nil
@@ -15,7 +15,7 @@ import (
 )
 
 // Heavily inspired by: https://github.com/chromedp/examples/blob/master/download_file/main.go
-func screenshot(s *http.Server) {
+func (r *rover) screenshot(s *http.Server) {
 	// ctx, cancel := chromedp.NewContext(context.Background(), chromedp.WithDebugf(log.Printf))
 	ctx, cancel := chromedp.NewContext(context.Background())
 	defer cancel()
@@ -24,7 +24,7 @@ func screenshot(s *http.Server) {
 	ctx, cancel = context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
 
-	url := fmt.Sprintf("http://%s", s.Addr)
+	url := serverURL(s.Addr)
 
 	// this will be used to capture the file name later
 	var downloadGUID string
@@ -56,17 +56,54 @@ func screenshot(s *http.Server) {
 	}
 	<-downloadComplete
 
-	e := moveFile(fmt.Sprintf("%v/%v", os.TempDir(), downloadGUID), "./rover.svg")
+	imagePath, err := r.outputPath("rover.svg")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	e := moveFile(fmt.Sprintf("%v/%v", os.TempDir(), downloadGUID), imagePath)
 	if e != nil {
 		log.Fatal(e)
 	}
 
-	log.Println("Image generation complete.")
+	if r.ImageSummary {
+		if err := r.writeImageSummary(imagePath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if r.ImageLegend {
+		if err := writeImageLegend(imagePath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Printf("Image generation complete: %s", imagePath)
+	r.Manifest.Image = artifactPath(imagePath)
 
 	// Shutdown http server
 	s.Shutdown(context.Background())
 }
 
+// copyFile copies sourcePath to destPath, overwriting destPath if it exists
+func copyFile(sourcePath, destPath string) error {
+	inputFile, err := os.Open(sourcePath)
+	if err != nil {
+		return fmt.Errorf("could not open source file: %s", err)
+	}
+	defer inputFile.Close()
+	outputFile, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("could not open dest file: %s", err)
+	}
+	defer outputFile.Close()
+	_, err = io.Copy(outputFile, inputFile)
+	if err != nil {
+		return fmt.Errorf("writing to output file failed: %s", err)
+	}
+	return nil
+}
+
 // This function resolves the "invalid cross-device link" error for moving files
 // between volumes for Docker.
 // https://gist.github.com/var23rav/23ae5d0d4d830aff886c3c970b8f6c6b
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"net"
 	"net/http"
@@ -12,68 +13,203 @@ import (
 	// tfjson "github.com/hashicorp/terraform-json"
 )
 
-func (ro *rover) startServer(ipPort string, frontendFS http.Handler) error {
+func (ro *rover) startServer(ipPort string, fe fs.FS) error {
 
 	m := http.NewServeMux()
-	s := http.Server{Addr: ipPort, Handler: m}
+	s := ro.httpServer(ipPort, ro.rateLimitHandler(gzipHandler(m)))
 
-	m.Handle("/", frontendFS)
-	m.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		// simple healthcheck
-		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "application/json")
-		io.WriteString(w, `{"alive": true}`)
-	})
-	m.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
-		fileType := strings.Replace(r.URL.Path, "/api/", "", 1)
+	if !ro.APIOnly {
+		m.Handle("/", staticCacheHandler(fe, http.FileServer(http.FS(fe))))
+		m.HandleFunc("/config.json", ro.handleFrontendConfig)
+	}
+	m.HandleFunc("/health", handleHealth)
+	m.HandleFunc("/api/version", handleVersion)
+	m.HandleFunc("/metrics", handleMetrics)
+	if ro.Profile {
+		registerPprof(m)
+	}
+	ro.registerAssets(m, "", fe)
+
+	l, err := net.Listen("tcp", ipPort)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// With port 0, the OS picks a free port
+	s.Addr = l.Addr().String()
+	logStatus(COLOR_GREEN, "Rover is running on %s (%s)", s.Addr, serverURL(s.Addr))
+
+	// The browser can connect now because the listening socket is open.
+	if ro.GenImage {
+		go ro.screenshot(s)
+	}
+
+	// Start the blocking server loop.
+	return s.Serve(l)
+
+}
+
+// serverURL returns the URL of a server listening on addr. Servers listening on all
+// interfaces are reached on localhost.
+func serverURL(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Sprintf("http://%s", addr)
+	}
+
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, port))
+}
+
+// httpServer returns a server for handler with the configured timeouts, so slow or
+// hung clients can't hold connections open indefinitely
+func (ro *rover) httpServer(ipPort string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ipPort,
+		Handler:           handler,
+		ReadHeaderTimeout: ro.ReadTimeout,
+		ReadTimeout:       ro.ReadTimeout,
+		WriteTimeout:      ro.WriteTimeout,
+		IdleTimeout:       ro.IdleTimeout,
+	}
+}
+
+// handleHealth is a simple healthcheck
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, `{"alive": true}`)
+}
+
+// handleVersion returns the Rover version, so scripts can check compatibility
+func handleVersion(w http.ResponseWriter, r *http.Request) {
+	j, err := json.Marshal(map[string]string{"version": VERSION})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error producing version JSON: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	enableCors(&w)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(j)
+}
+
+// registerAssets adds the download and API handlers serving the rover's assets under prefix.
+// With --apiOnly, the standalone zip isn't downloadable since it contains the frontend.
+func (ro *rover) registerAssets(m *http.ServeMux, prefix string, fe fs.FS) {
+	if !ro.APIOnly {
+		m.HandleFunc(prefix+"/download", func(w http.ResponseWriter, r *http.Request) {
+			// Build the standalone zip in memory so a failure doesn't send a partial file
+			var buf bytes.Buffer
+			if err := ro.snapshot().writeZip(fe, &buf); err != nil {
+				http.Error(w, fmt.Sprintf("Error producing standalone zip: %s", err), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/zip")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s.zip", ro.ZipFileName)))
+			io.Copy(w, &buf)
+		})
+	}
+	if ro.AllowRefresh {
+		m.HandleFunc(prefix+"/api/refresh", ro.handleRefresh)
+	}
+	if ro.AllowUpload {
+		m.HandleFunc(prefix+"/api/upload", ro.handleUpload)
+	}
+	m.HandleFunc(prefix+"/ws/progress", ro.handleProgress)
+	m.HandleFunc(prefix+"/api/graph/blast", ro.handleBlastRadius)
+	m.HandleFunc(prefix+"/api/resource", ro.handleResourceDiff)
+	m.HandleFunc(prefix+"/api/resources", ro.handleResources)
+	if ro.EventLog != "" {
+		m.HandleFunc(prefix+"/api/event", ro.handleEvent)
+	}
+	m.HandleFunc(prefix+"/api/", func(w http.ResponseWriter, r *http.Request) {
+		fileType := strings.TrimPrefix(r.URL.Path, prefix+"/api/")
 
 		var j []byte
 		var err error
 
 		enableCors(&w)
 
+		snap := ro.snapshot()
+
+		// Responses only change once the assets are generated again
+		if notModified(w, r, fmt.Sprintf(`W/"%d"`, snap.GeneratedAt.UnixNano()), snap.GeneratedAt) {
+			return
+		}
+
 		switch fileType {
 		case "plan":
-			j, err = json.Marshal(ro.Plan)
+			j, err = json.Marshal(snap.Plan)
 			if err != nil {
 				io.WriteString(w, fmt.Sprintf("Error producing plan JSON: %s\n", err))
 			}
 		case "rso":
-			j, err = json.Marshal(ro.RSO)
+			j, err = json.Marshal(snap.RSO)
 			if err != nil {
 				io.WriteString(w, fmt.Sprintf("Error producing rso JSON: %s\n", err))
 			}
 		case "map":
-			j, err = json.Marshal(ro.Map)
+			m := snap.Map
+			if collapse := r.URL.Query().Get("collapse"); collapse != "" && m != nil {
+				m, err = m.collapseModules(strings.Split(collapse, ","))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			j, err = json.Marshal(m)
 			if err != nil {
 				io.WriteString(w, fmt.Sprintf("Error producing map JSON: %s\n", err))
 			}
 		case "graph":
-			j, err = json.Marshal(ro.Graph)
+			j, err = json.Marshal(snap.Graph)
 			if err != nil {
 				io.WriteString(w, fmt.Sprintf("Error producing graph JSON: %s\n", err))
 			}
+		case "cost":
+			var cost json.RawMessage
+			if snap.RSO != nil {
+				cost = snap.RSO.Cost
+			}
+			j, err = json.Marshal(cost)
+			if err != nil {
+				io.WriteString(w, fmt.Sprintf("Error producing cost JSON: %s\n", err))
+			}
+		case "cytoscape":
+			j, err = json.Marshal(snap.cytoscapeGraph())
+			if err != nil {
+				io.WriteString(w, fmt.Sprintf("Error producing cytoscape JSON: %s\n", err))
+			}
+		case "history":
+			j, err = json.Marshal(snap.History)
+			if err != nil {
+				io.WriteString(w, fmt.Sprintf("Error producing history JSON: %s\n", err))
+			}
+		case "policies":
+			j, err = json.Marshal(snap.Policies)
+			if err != nil {
+				io.WriteString(w, fmt.Sprintf("Error producing policies JSON: %s\n", err))
+			}
+		case "diagnostics":
+			j, err = json.Marshal(snap.Diagnostics)
+			if err != nil {
+				io.WriteString(w, fmt.Sprintf("Error producing diagnostics JSON: %s\n", err))
+			}
+		case "meta":
+			j, err = json.Marshal(snap.meta())
+			if err != nil {
+				io.WriteString(w, fmt.Sprintf("Error producing meta JSON: %s\n", err))
+			}
 		default:
-			io.WriteString(w, "Please enter a valid file type: plan, rso, map, graph\n")
+			io.WriteString(w, "Please enter a valid file type: plan, rso, map, graph, cytoscape, cost, history, policies, diagnostics, meta\n")
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		io.Copy(w, bytes.NewReader(j))
 	})
-
-	log.Printf("Rover is running on %s", ipPort)
-
-	l, err := net.Listen("tcp", ipPort)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// The browser can connect now because the listening socket is open.
-	if ro.GenImage {
-		go screenshot(&s)
-	}
-
-	// Start the blocking server loop.
-	return s.Serve(l)
-
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// getStatePlan reads the JSON state at r.StateJSONPath, the output of terraform show -json without
+// a plan file, and visualizes it as a plan that changes nothing
+func (r *rover) getStatePlan() error {
+	log.Println("Using provided JSON state...")
+
+	stateJson, err := os.ReadFile(r.StateJSONPath)
+	if err != nil {
+		return fmt.Errorf("unable to read State (%s): %s", r.StateJSONPath, err)
+	}
+
+	state := &tfjson.State{}
+	if err := json.Unmarshal(stateJson, state); err != nil {
+		return withKind(ErrPlanParse, fmt.Errorf("unable to read State (%s): %w", r.StateJSONPath, err))
+	}
+
+	r.Plan = statePlan(state)
+
+	return nil
+}
+
+// statePlan synthesizes a plan with a no-op change for every resource in the state. The state has
+// no configuration, so like for partial plans, the module hierarchy is derived from the addresses
+// and references between resources are missing.
+func statePlan(state *tfjson.State) *tfjson.Plan {
+	plan := &tfjson.Plan{
+		FormatVersion:    PARTIAL_PLAN_FORMAT_VERSION,
+		TerraformVersion: state.TerraformVersion,
+		PriorState:       state,
+	}
+
+	if state.Values == nil || state.Values.RootModule == nil {
+		plan.PlannedValues = &tfjson.StateValues{RootModule: &tfjson.StateModule{}}
+		plan.Config = &tfjson.Config{RootModule: &tfjson.ConfigModule{}}
+		return plan
+	}
+
+	plan.ResourceChanges = stateResourceChanges(nil, state.Values.RootModule)
+	completePartialPlan(plan)
+
+	// The state's own values keep attributes completePartialPlan doesn't copy, like sensitive values
+	plan.PlannedValues = state.Values
+
+	outputChanges := map[string]*tfjson.Change{}
+	for name, output := range state.Values.Outputs {
+		outputChanges[name] = &tfjson.Change{
+			Actions:         tfjson.Actions{tfjson.ActionNoop},
+			Before:          output.Value,
+			After:           output.Value,
+			BeforeSensitive: output.Sensitive,
+			AfterSensitive:  output.Sensitive,
+		}
+	}
+	plan.OutputChanges = outputChanges
+
+	return plan
+}
+
+// stateResourceChanges appends a no-op change for the managed and data resources of the module
+// and its child modules to changes
+func stateResourceChanges(changes []*tfjson.ResourceChange, module *tfjson.StateModule) []*tfjson.ResourceChange {
+	for _, rst := range module.Resources {
+		var sensitive interface{}
+		if len(rst.SensitiveValues) > 0 {
+			json.Unmarshal(rst.SensitiveValues, &sensitive)
+		}
+
+		changes = append(changes, &tfjson.ResourceChange{
+			Address:       rst.Address,
+			ModuleAddress: module.Address,
+			Mode:          rst.Mode,
+			Type:          rst.Type,
+			Name:          rst.Name,
+			Index:         rst.Index,
+			ProviderName:  rst.ProviderName,
+			Change: &tfjson.Change{
+				Actions:         tfjson.Actions{tfjson.ActionNoop},
+				Before:          rst.AttributeValues,
+				After:           rst.AttributeValues,
+				BeforeSensitive: sensitive,
+				AfterSensitive:  sensitive,
+			},
+		})
+	}
+
+	for _, childModule := range module.ChildModules {
+		changes = stateResourceChanges(changes, childModule)
+	}
+
+	return changes
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// printStats writes tables of the plan's resource changes by action, resource type and provider
+func (r *rover) printStats(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	s := r.summary()
+	fmt.Fprintln(tw, "ACTION\tCOUNT")
+	for _, row := range []struct {
+		action Action
+		count  int
+	}{
+		{ActionCreate, s.Create},
+		{ActionRead, s.Read},
+		{ActionUpdate, s.Update},
+		{ActionDelete, s.Delete},
+		{ActionReplace, s.Replace},
+		{ActionNoop, s.NoOp},
+	} {
+		fmt.Fprintf(tw, "%s\t%d\n", row.action, row.count)
+	}
+	fmt.Fprintf(tw, "total\t%d\n", s.Total)
+	fmt.Fprintf(tw, "import\t%d\n", s.Import)
+
+	byType := map[string]int{}
+	if r.Plan != nil {
+		for _, rc := range r.Plan.ResourceChanges {
+			byType[rc.Type]++
+		}
+	}
+
+	fmt.Fprintln(tw)
+	writeCounts(tw, "TYPE", byType)
+	fmt.Fprintln(tw)
+	writeProviderSummaries(tw, r.providerSummaries())
+
+	return tw.Flush()
+}
+
+// writeProviderSummaries writes the action counts of each provider as rows sorted by descending total, then by name
+func writeProviderSummaries(w io.Writer, providers map[string]*Summary) {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if providers[names[i]].Total != providers[names[j]].Total {
+			return providers[names[i]].Total > providers[names[j]].Total
+		}
+		return names[i] < names[j]
+	})
+
+	fmt.Fprintln(w, "PROVIDER\tCREATE\tREAD\tUPDATE\tDELETE\tREPLACE\tNO-OP\tTOTAL")
+	for _, name := range names {
+		s := providers[name]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n", name, s.Create, s.Read, s.Update, s.Delete, s.Replace, s.NoOp, s.Total)
+	}
+}
+
+// writeCounts writes counts as rows sorted by descending count, then by name
+func writeCounts(w io.Writer, header string, counts map[string]int) {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	fmt.Fprintf(w, "%s\tCOUNT\n", header)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%d\n", name, counts[name])
+	}
+}
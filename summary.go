@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// Summary counts the resource changes in the plan by action
+type Summary struct {
+	Total   int `json:"total"`
+	Create  int `json:"create"`
+	Read    int `json:"read"`
+	Update  int `json:"update"`
+	Delete  int `json:"delete"`
+	Replace int `json:"replace"`
+	NoOp    int `json:"no-op"`
+	// Import counts the resources the plan imports, which are also counted under their action
+	Import int `json:"import"`
+}
+
+// summary tallies r.Plan.ResourceChanges
+func (r *rover) summary() Summary {
+	s := Summary{}
+
+	if r.Plan == nil {
+		return s
+	}
+
+	for _, rc := range r.Plan.ResourceChanges {
+		s.add(rc)
+	}
+
+	return s
+}
+
+// providerSummaries tallies r.Plan.ResourceChanges by provider
+func (r *rover) providerSummaries() map[string]*Summary {
+	providers := make(map[string]*Summary)
+
+	if r.Plan == nil {
+		return providers
+	}
+
+	for _, rc := range r.Plan.ResourceChanges {
+		if rc.Change == nil {
+			continue
+		}
+
+		provider := resourceProvider(rc)
+		if _, ok := providers[provider]; !ok {
+			providers[provider] = &Summary{}
+		}
+		providers[provider].add(rc)
+	}
+
+	return providers
+}
+
+// add counts the resource change under its action
+func (s *Summary) add(rc *tfjson.ResourceChange) {
+	if rc.Change == nil {
+		return
+	}
+
+	s.Total++
+
+	if rc.Change.Importing != nil {
+		s.Import++
+	}
+
+	switch {
+	case rc.Change.Actions.Replace():
+		s.Replace++
+	case rc.Change.Actions.Create():
+		s.Create++
+	case rc.Change.Actions.Read():
+		s.Read++
+	case rc.Change.Actions.Update():
+		s.Update++
+	case rc.Change.Actions.Delete():
+		s.Delete++
+	default:
+		s.NoOp++
+	}
+}
+
+// resourceProvider returns the provider of the resource change, derived from
+// the resource type if the plan doesn't name it
+func resourceProvider(rc *tfjson.ResourceChange) string {
+	if rc.ProviderName != "" {
+		return rc.ProviderName
+	}
+
+	provider, _, _ := strings.Cut(rc.Type, "_")
+	return provider
+}
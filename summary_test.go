@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func resourceChange(address, resourceType, provider string, actions ...tfjson.Action) *tfjson.ResourceChange {
+	return &tfjson.ResourceChange{
+		Address:      address,
+		Type:         resourceType,
+		ProviderName: provider,
+		Change:       &tfjson.Change{Actions: actions},
+	}
+}
+
+func TestProviderSummaries(t *testing.T) {
+	const (
+		aws    = "registry.terraform.io/hashicorp/aws"
+		google = "registry.terraform.io/hashicorp/google"
+	)
+
+	r := &rover{Plan: &tfjson.Plan{ResourceChanges: []*tfjson.ResourceChange{
+		resourceChange("aws_instance.web", "aws_instance", aws, tfjson.ActionCreate),
+		resourceChange("aws_instance.db", "aws_instance", aws, tfjson.ActionDelete, tfjson.ActionCreate),
+		resourceChange("aws_s3_bucket.logs", "aws_s3_bucket", aws, tfjson.ActionUpdate),
+		resourceChange("data.aws_ami.ubuntu", "aws_ami", aws, tfjson.ActionRead),
+		resourceChange("google_compute_instance.vm", "google_compute_instance", google, tfjson.ActionCreate, tfjson.ActionDelete),
+		resourceChange("google_storage_bucket.old", "google_storage_bucket", google, tfjson.ActionDelete),
+		resourceChange("google_storage_bucket.new", "google_storage_bucket", google, tfjson.ActionNoop),
+		// Partial plans may not name the provider, which is derived from the type then
+		resourceChange("random_id.suffix", "random_id", "", tfjson.ActionCreate),
+		{Address: "null_resource.unchanged", Type: "null_resource"},
+	}}}
+
+	want := map[string]*Summary{
+		aws:      {Total: 4, Create: 1, Replace: 1, Update: 1, Read: 1},
+		google:   {Total: 3, Replace: 1, Delete: 1, NoOp: 1},
+		"random": {Total: 1, Create: 1},
+	}
+
+	got := r.providerSummaries()
+	if !reflect.DeepEqual(got, want) {
+		for provider, s := range got {
+			t.Logf("%s: %+v", provider, *s)
+		}
+		t.Errorf("providerSummaries() doesn't match %d providers", len(want))
+	}
+
+	// The per-provider tallies add up to the plan's summary
+	total := Summary{}
+	for _, s := range got {
+		total.Total += s.Total
+		total.Create += s.Create
+		total.Read += s.Read
+		total.Update += s.Update
+		total.Delete += s.Delete
+		total.Replace += s.Replace
+		total.NoOp += s.NoOp
+	}
+	if total != r.summary() {
+		t.Errorf("provider summaries add up to %+v, want %+v", total, r.summary())
+	}
+}
+
+func TestProviderSummariesWithoutPlan(t *testing.T) {
+	r := &rover{}
+	if got := r.providerSummaries(); len(got) != 0 {
+		t.Errorf("providerSummaries() = %v, want no providers", got)
+	}
+}
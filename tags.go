@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const (
+	// UNTAGGED_GROUP is the group of resources without the --groupByTag tag
+	UNTAGGED_GROUP string = "untagged"
+	// MIXED_GROUP is the group of collapsed resources whose instances are in different groups
+	MIXED_GROUP string = "mixed"
+)
+
+// TAG_ATTRIBUTES are the attributes resources keep their tags in, by the provider prefix of the
+// resource type. tags_all comes first since it includes the provider's default tags.
+var TAG_ATTRIBUTES = map[string][]string{
+	"aws":         {"tags_all", "tags"},
+	"azurerm":     {"tags"},
+	"google":      {"labels"},
+	"google-beta": {"labels"},
+}
+
+// DEFAULT_TAG_ATTRIBUTES are the tag attributes of resources of other providers
+var DEFAULT_TAG_ATTRIBUTES = []string{"tags", "labels"}
+
+// resourceTag returns the planned value of the resource's tag, or false if it isn't tagged with it
+func resourceTag(rc *tfjson.ResourceChange, key string) (string, bool) {
+	if rc.Change == nil {
+		return "", false
+	}
+
+	// Resources to be deleted are only in the prior state
+	values := rc.Change.After
+	if rc.Change.Actions.Delete() {
+		values = rc.Change.Before
+	}
+	attributes := attributeValues(values)
+
+	provider, _, _ := strings.Cut(rc.Type, "_")
+	if strings.HasSuffix(rc.ProviderName, "/google-beta") {
+		provider = "google-beta"
+	}
+	tagAttributes, ok := TAG_ATTRIBUTES[provider]
+	if !ok {
+		tagAttributes = DEFAULT_TAG_ATTRIBUTES
+	}
+
+	for _, tagAttribute := range tagAttributes {
+		tags, ok := attributes[tagAttribute].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, ok := tags[key]; ok && value != nil {
+			return fmt.Sprintf("%v", value), true
+		}
+	}
+
+	return "", false
+}
+
+// groupByTag puts the resource nodes in groups named after the value of their --groupByTag tag.
+// Collapsed resources are grouped by the tag of their instances. Each group is a compound node
+// within the original parent of its resources.
+func (r *rover) groupByTag(nodes []Node) []Node {
+	if r.Plan == nil {
+		return nodes
+	}
+
+	groups := map[string]string{}
+	resourceGroups := map[string]string{}
+	for _, rc := range r.Plan.ResourceChanges {
+		group, ok := resourceTag(rc, r.GroupByTag)
+		if !ok {
+			group = UNTAGGED_GROUP
+		}
+		groups[rc.Address] = group
+
+		if loc := instanceKeyPattern.FindStringIndex(rc.Address); loc != nil {
+			resource := rc.Address[:loc[0]]
+			if g, ok := resourceGroups[resource]; ok && g != group {
+				group = MIXED_GROUP
+			}
+			resourceGroups[resource] = group
+		}
+	}
+
+	for i, n := range nodes {
+		group, ok := groups[n.Data.ID]
+		if g, collapsed := resourceGroups[n.Data.ID]; collapsed && n.Data.Instances > 0 {
+			group, ok = g, true
+		}
+		if !ok {
+			continue
+		}
+
+		nodes[i].Data.Group = group
+	}
+
+	// Group nodes are inserted before their first resource, since the frontend adds parents before children
+	grouped := make([]Node, 0, len(nodes))
+	groupNodes := map[string]bool{}
+	for _, n := range nodes {
+		if n.Data.Group != "" {
+			id := fmt.Sprintf("%s (%s=%s)", n.Data.Parent, r.GroupByTag, n.Data.Group)
+			if !groupNodes[id] {
+				grouped = append(grouped, Node{
+					Data: NodeData{
+						ID:          id,
+						Label:       fmt.Sprintf("%s=%s", r.GroupByTag, n.Data.Group),
+						Type:        ResourceTypeGroup,
+						Parent:      n.Data.Parent,
+						ParentColor: n.Data.ParentColor,
+						Group:       n.Data.Group,
+					},
+					Classes: "group",
+				})
+				groupNodes[id] = true
+			}
+			n.Data.Parent = id
+		}
+		grouped = append(grouped, n)
+	}
+
+	return grouped
+}
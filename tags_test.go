@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestGroupByTag(t *testing.T) {
+	tagged := func(address string, environment string) *tfjson.ResourceChange {
+		rc := resourceChange(address, "aws_instance", "registry.terraform.io/hashicorp/aws", tfjson.ActionCreate)
+		rc.Change.After = map[string]interface{}{"tags": map[string]interface{}{"environment": environment}}
+		return rc
+	}
+
+	r := &rover{
+		GroupByTag: "environment",
+		Plan: &tfjson.Plan{ResourceChanges: []*tfjson.ResourceChange{
+			tagged("aws_instance.web", "prod"),
+			tagged("aws_instance.api", "prod"),
+			tagged("aws_instance.test", "staging"),
+			resourceChange("aws_instance.legacy", "aws_instance", "registry.terraform.io/hashicorp/aws", tfjson.ActionCreate),
+		}},
+	}
+
+	nodes := []Node{
+		{Data: NodeData{ID: "aws_instance", Type: ResourceTypeResource}},
+		{Data: NodeData{ID: "aws_instance.web", Parent: "aws_instance"}},
+		{Data: NodeData{ID: "aws_instance.test", Parent: "aws_instance"}},
+		{Data: NodeData{ID: "aws_instance.api", Parent: "aws_instance"}},
+		{Data: NodeData{ID: "aws_instance.legacy", Parent: "aws_instance"}},
+	}
+
+	groupNode := func(group string) Node {
+		return Node{
+			Data: NodeData{
+				ID:     "aws_instance (environment=" + group + ")",
+				Label:  "environment=" + group,
+				Type:   ResourceTypeGroup,
+				Parent: "aws_instance",
+				Group:  group,
+			},
+			Classes: "group",
+		}
+	}
+
+	want := []Node{
+		{Data: NodeData{ID: "aws_instance", Type: ResourceTypeResource}},
+		groupNode("prod"),
+		{Data: NodeData{ID: "aws_instance.web", Parent: "aws_instance (environment=prod)", Group: "prod"}},
+		groupNode("staging"),
+		{Data: NodeData{ID: "aws_instance.test", Parent: "aws_instance (environment=staging)", Group: "staging"}},
+		{Data: NodeData{ID: "aws_instance.api", Parent: "aws_instance (environment=prod)", Group: "prod"}},
+		groupNode(UNTAGGED_GROUP),
+		{Data: NodeData{ID: "aws_instance.legacy", Parent: "aws_instance (environment=untagged)", Group: UNTAGGED_GROUP}},
+	}
+
+	got := r.groupByTag(nodes)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupByTag() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,7 @@
+resource "null_resource" "a" {
+  triggers = { b = null_resource.b.id }
+}
+resource "null_resource" "b" {
+  count = 2
+}
+resource "null_resource" "c" {}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// TFC_RUN_TIMEOUT is the maximum time to wait for a new run to produce a plan
+const TFC_RUN_TIMEOUT = 5 * time.Minute
+
+// TFC_RUN_PAGE_SIZE is the number of runs listed per request when searching a workspace's runs
+const TFC_RUN_PAGE_SIZE = 100
+
+// TFC_WORKSPACE_PAGE_SIZE is the number of workspaces listed per request when selecting workspaces by tag
+const TFC_WORKSPACE_PAGE_SIZE = 100
+
+// getTFCPlans retrieves the latest plan of every specified Terraform Cloud workspace, or of every
+// workspace with --tfcWorkspaceTag. Plans from multiple workspaces are merged into a single plan.
+func (r *rover) getTFCPlans() error {
+	client, err := r.tfcClient()
+	if err != nil {
+		return err
+	}
+
+	workspaceNames, err := r.tfcWorkspaces(client)
+	if err != nil {
+		return err
+	}
+
+	plans := []*tfjson.Plan{}
+	applyResults := map[string]string{}
+	for _, workspaceName := range workspaceNames {
+		// Merged plans prefix addresses with a module named after the workspace
+		prefix := ""
+		if len(workspaceNames) > 1 {
+			prefix = fmt.Sprintf("module.%s", workspaceName)
+		}
+
+		plan, results, err := r.getTFCPlan(client, workspaceName, prefix)
+		if err != nil {
+			return err
+		}
+		plans = append(plans, plan)
+
+		for address, result := range results {
+			if prefix != "" {
+				address = prefixAddress(prefix, address)
+			}
+			applyResults[address] = result
+		}
+	}
+
+	if r.ShowApplyResult {
+		r.ApplyResults = applyResults
+	}
+
+	if len(plans) == 1 {
+		r.Plan = plans[0]
+		return nil
+	}
+
+	log.Printf("Merging plans from %d workspaces...", len(plans))
+	plan, err := mergePlans(workspaceNames, plans)
+	if err != nil {
+		return err
+	}
+	r.Plan = plan
+
+	return nil
+}
+
+// usesTFC returns true if plans are retrieved from Terraform Cloud
+func (r *rover) usesTFC() bool {
+	return len(r.TFCWorkspaceNames) > 0 || r.TFCWorkspaceTag != ""
+}
+
+// tfcWorkspaces returns the names of the workspaces to retrieve plans from, which are the
+// workspaces carrying --tfcWorkspaceTag if it's set, sorted by name
+func (r *rover) tfcWorkspaces(client *tfe.Client) ([]string, error) {
+	if r.TFCWorkspaceTag == "" {
+		return r.TFCWorkspaceNames, nil
+	}
+
+	options := &tfe.WorkspaceListOptions{
+		ListOptions: tfe.ListOptions{PageNumber: 1, PageSize: TFC_WORKSPACE_PAGE_SIZE},
+		Tags:        r.TFCWorkspaceTag,
+	}
+
+	names := []string{}
+	for {
+		workspaces, err := client.Workspaces.List(context.Background(), r.TFCOrgName, options)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list workspaces tagged %s in %s organization. %s", r.TFCWorkspaceTag, r.TFCOrgName, err)
+		}
+
+		for _, ws := range workspaces.Items {
+			names = append(names, ws.Name)
+		}
+
+		if workspaces.Pagination == nil || workspaces.Pagination.NextPage == 0 {
+			break
+		}
+		options.PageNumber = workspaces.Pagination.NextPage
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no workspaces tagged %s found in %s organization", r.TFCWorkspaceTag, r.TFCOrgName)
+	}
+
+	sort.Strings(names)
+	log.Printf("Found %d workspaces tagged %s: %s", len(names), r.TFCWorkspaceTag, strings.Join(names, ", "))
+
+	return names, nil
+}
+
+// tfcClient connects to Terraform Cloud with the TFC_TOKEN environment variable
+func (r *rover) tfcClient() (*tfe.Client, error) {
+	tfcToken := os.Getenv("TFC_TOKEN")
+
+	if tfcToken == "" {
+		return nil, ErrNoTFCToken
+	}
+
+	if r.TFCOrgName == "" {
+		return nil, ErrNoTFCOrg
+	}
+
+	config := &tfe.Config{
+		Token:      tfcToken,
+		HTTPClient: r.tfcHTTPClient(),
+	}
+
+	client, err := tfe.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to Terraform Cloud. %s", err)
+	}
+
+	return client, nil
+}
+
+// tfcHTTPClient returns the HTTP client used for Terraform Cloud, which honors the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+func (r *rover) tfcHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if r.TFCInsecure {
+		logStatus(COLOR_YELLOW, "WARNING: --tfcInsecure is set, TLS certificates of Terraform Cloud and proxies are NOT verified!")
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+// getTFCPlan retrieves the latest plan from a Terraform Cloud workspace, creating a new run
+// first if --tfcNewRun is set. With --showApplyResult, the apply outcome of the plan's
+// resource changes is returned too. The previous addresses of moved resources are recorded with
+// prefix, the module prefix of the plan's addresses once merged.
+func (r *rover) getTFCPlan(client *tfe.Client, workspaceName string, prefix string) (*tfjson.Plan, map[string]string, error) {
+	// Get TFC Workspace
+	ws, err := client.Workspaces.Read(context.Background(), r.TFCOrgName, workspaceName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to list workspace %s in %s organization. %s", workspaceName, r.TFCOrgName, err)
+	}
+
+	// Retrieve all runs from specified TFC workspace
+	var runs *tfe.RunList
+	if r.TFCSince.IsZero() {
+		runs, err = client.Runs.List(context.Background(), ws.ID, &tfe.RunListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to retrieve plan from %s in %s organization. %s", workspaceName, r.TFCOrgName, err)
+		}
+	} else {
+		run, err := findTFCRunBefore(client, ws.ID, r.TFCSince)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to retrieve plan from %s in %s organization. %s", workspaceName, r.TFCOrgName, err)
+		}
+		if run == nil {
+			return nil, nil, fmt.Errorf("no runs created on or before %s found in %s in %s organization", r.TFCSince.Format(time.RFC3339), workspaceName, r.TFCOrgName)
+		}
+
+		log.Printf("Using run %s created at %s in %s workspace", run.ID, run.CreatedAt.Format(time.RFC3339), workspaceName)
+		runs = &tfe.RunList{Items: []*tfe.Run{run}}
+	}
+
+	if len(runs.Items) == 0 && !r.TFCNewRun {
+		return nil, nil, fmt.Errorf("no runs found in %s in %s organization", workspaceName, r.TFCOrgName)
+	}
+
+	var run *tfe.Run
+	var planID string
+
+	if len(runs.Items) > 0 {
+		run = runs.Items[0]
+
+		// Get most recent plan item
+		planID = run.Plan.ID
+
+		// Run hasn't been applied or discarded, therefore is still "actionable" by user
+		runIsActionable := run.StatusTimestamps.AppliedAt.IsZero() && run.StatusTimestamps.DiscardedAt.IsZero()
+
+		if runIsActionable && r.TFCNewRun {
+			return nil, nil, fmt.Errorf("did not create new run. %s in %s in %s is still active", run.ID, workspaceName, r.TFCOrgName)
+		}
+	}
+
+	// If latest run is not actionable, rover will create new run
+	if r.TFCNewRun {
+		// Create new run in specified TFC workspace
+		newRun, err := client.Runs.Create(context.Background(), tfe.RunCreateOptions{
+			Refresh:   &TRUE,
+			Workspace: ws,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to generate new run from %s in %s organization. %s", workspaceName, r.TFCOrgName, err)
+		}
+
+		run = newRun
+
+		log.Printf("Starting new Terraform Cloud run in %s workspace...", workspaceName)
+
+		// Cancel the new run if Rover is interrupted or gives up waiting for it, so it isn't left running
+		ctx := context.Background()
+		if !r.TFCNoCancel {
+			var stop context.CancelFunc
+			ctx, stop = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+		}
+
+		planID, err = r.waitForTFCRun(ctx, client, newRun.ID)
+		if ctx.Err() != nil {
+			r.cancelTFCRun(client, newRun.ID)
+			return nil, nil, fmt.Errorf("interrupted while waiting for run %s in %s in %s organization", newRun.ID, workspaceName, r.TFCOrgName)
+		}
+		if errors.Is(err, ErrTFCRunTimeout) && !r.TFCNoCancel {
+			r.cancelTFCRun(client, newRun.ID)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s in %s in %s organization", err, workspaceName, r.TFCOrgName)
+		}
+
+		log.Printf("Run %s planned!", newRun.ID)
+	}
+
+	// Get most recent plan file
+	planBytes, err := client.Plans.ReadJSONOutput(context.Background(), planID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to retrieve plan from %s in %s organization. %s", workspaceName, r.TFCOrgName, err)
+	}
+	// If empty plan file
+	if string(planBytes) == "" {
+		return nil, nil, fmt.Errorf("empty plan, check run %s in %s in %s is not pending", run.ID, workspaceName, r.TFCOrgName)
+	}
+
+	plan := &tfjson.Plan{}
+	if err := json.Unmarshal(planBytes, plan); err != nil {
+		return nil, nil, withKind(ErrPlanParse, fmt.Errorf("unable to parse plan (ID: %s) from %s in %s organization.: %w", planID, workspaceName, r.TFCOrgName, err))
+	}
+	r.readPreviousAddresses(prefix, planBytes)
+	r.readReplaceReasons(prefix, planBytes)
+
+	if r.ShowPolicies {
+		checks, err := getTFCPolicyChecks(client, run, workspaceName, plan, prefix)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s in %s in %s organization", err, workspaceName, r.TFCOrgName)
+		}
+		log.Printf("Retrieved %d policy checks of run %s in %s workspace", len(checks), run.ID, workspaceName)
+		r.Policies = append(r.Policies, checks...)
+	}
+
+	if !r.ShowApplyResult {
+		return plan, nil, nil
+	}
+
+	results, err := getTFCApplyResults(client, run, plan)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s in %s in %s organization", err, workspaceName, r.TFCOrgName)
+	}
+	log.Printf("Retrieved apply results of run %s in %s workspace", run.ID, workspaceName)
+
+	return plan, results, nil
+}
+
+// findTFCRunBefore returns the newest run of the workspace created on or before t, or nil if there is none.
+// Runs are listed newest first, so pages are only fetched until an old enough run is found.
+func findTFCRunBefore(client *tfe.Client, workspaceID string, t time.Time) (*tfe.Run, error) {
+	options := &tfe.RunListOptions{ListOptions: tfe.ListOptions{PageNumber: 1, PageSize: TFC_RUN_PAGE_SIZE}}
+
+	for {
+		runs, err := client.Runs.List(context.Background(), workspaceID, options)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, run := range runs.Items {
+			if !run.CreatedAt.After(t) {
+				return run, nil
+			}
+		}
+
+		if runs.Pagination == nil || runs.Pagination.NextPage == 0 {
+			return nil, nil
+		}
+		options.PageNumber = runs.Pagination.NextPage
+	}
+}
+
+// waitForTFCRun waits a maximum of 5 mins for a run to finish planning and returns its plan ID
+func (r *rover) waitForTFCRun(ctx context.Context, client *tfe.Client, runID string) (string, error) {
+	deadline := time.Now().Add(TFC_RUN_TIMEOUT)
+
+	var planID string
+	for planID == "" {
+		run, err := client.Runs.Read(ctx, runID)
+		if err != nil {
+			return "", fmt.Errorf("unable to retrieve run %s. %s", runID, err)
+		}
+
+		if run.Plan != nil {
+			planID = run.Plan.ID
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return "", withKind(ErrTFCRunTimeout, fmt.Errorf("timeout waiting for plan of run %s to complete", runID))
+		}
+
+		if err := sleepContext(ctx, r.TFCPollInterval); err != nil {
+			return "", err
+		}
+		log.Printf("Waiting for run %s to start planning...", runID)
+	}
+
+	// The plan JSON isn't available before the plan is finished
+	for {
+		plan, err := client.Plans.Read(ctx, planID)
+		if err != nil {
+			return "", fmt.Errorf("unable to retrieve plan %s. %s", planID, err)
+		}
+
+		switch plan.Status {
+		case tfe.PlanFinished:
+			// Finished plans are waited for instead of sleeping, --tfcPlanDelay only adds time if set
+			if r.TFCPlanDelay > 0 {
+				log.Printf("Waiting %s for the JSON output of plan %s...", r.TFCPlanDelay, planID)
+				if err := sleepContext(ctx, r.TFCPlanDelay); err != nil {
+					return "", err
+				}
+			}
+			return planID, nil
+		case tfe.PlanErrored, tfe.PlanCanceled, tfe.PlanUnreachable:
+			return "", fmt.Errorf("plan %s is %s", planID, plan.Status)
+		}
+
+		if time.Now().After(deadline) {
+			return "", withKind(ErrTFCRunTimeout, fmt.Errorf("timeout waiting for plan %s to finish (status: %s)", planID, plan.Status))
+		}
+
+		if err := sleepContext(ctx, r.TFCPollInterval); err != nil {
+			return "", err
+		}
+		log.Printf("Waiting for plan %s to finish (status: %s)...", planID, plan.Status)
+	}
+}
+
+// cancelTFCRun cancels a run, discarding it instead if it can't be cancelled anymore
+func (r *rover) cancelTFCRun(client *tfe.Client, runID string) {
+	log.Printf("Cancelling run %s...", runID)
+
+	comment := tfe.String("Cancelled by Rover")
+	err := client.Runs.Cancel(context.Background(), runID, tfe.RunCancelOptions{Comment: comment})
+	if err != nil {
+		err = client.Runs.Discard(context.Background(), runID, tfe.RunDiscardOptions{Comment: comment})
+	}
+	if err != nil {
+		log.Printf("Unable to cancel run %s, cancel it in Terraform Cloud: %s", runID, err)
+		return
+	}
+
+	log.Printf("Cancelled run %s", runID)
+}
+
+// sleepContext sleeps for d, returning early with the context's error if it is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// treeMarkers are the markers printed in front of resources by change action
+var treeMarkers = map[Action]string{
+	ActionCreate:  "+",
+	ActionRead:    "<=",
+	ActionUpdate:  "~",
+	ActionDelete:  "-",
+	ActionReplace: "-/+",
+}
+
+// printTree writes the module and resource hierarchy of r.Map as an ASCII tree
+func (r *rover) printTree(w io.Writer) {
+	fmt.Fprintln(w, r.Map.Path)
+	printTreeLevel(w, r.Map.Root, "")
+}
+
+func printTreeLevel(w io.Writer, resources map[string]*Resource, indent string) {
+	ids := make([]string, 0, len(resources))
+	for id := range resources {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for i, id := range ids {
+		re := resources[id]
+
+		branch, childIndent := "├── ", "│   "
+		if i == len(ids)-1 {
+			branch, childIndent = "└── ", "    "
+		}
+
+		label := id
+		if re.Type == ResourceTypeFile {
+			label = re.Name
+		}
+		if marker, ok := treeMarkers[re.ChangeAction]; ok {
+			label = fmt.Sprintf("%s %s", marker, label)
+		}
+
+		fmt.Fprintf(w, "%s%s%s\n", indent, branch, label)
+		printTreeLevel(w, re.Children, indent+childIndent)
+	}
+}
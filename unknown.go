@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// unknownAttributes returns the sorted paths of the attributes only known after apply, like
+// "id" or "tags.Name". after_unknown mirrors the structure of after, marking unknown values with true.
+func unknownAttributes(change *tfjson.Change) []string {
+	if change == nil {
+		return nil
+	}
+
+	paths := []string{}
+	collectUnknown("", change.AfterUnknown, &paths)
+	sort.Strings(paths)
+
+	return paths
+}
+
+func collectUnknown(path string, v interface{}, paths *[]string) {
+	switch v := v.(type) {
+	case bool:
+		if v && path != "" {
+			*paths = append(*paths, path)
+		}
+	case map[string]interface{}:
+		for name, child := range v {
+			childPath := name
+			if path != "" {
+				childPath = fmt.Sprintf("%s.%s", path, name)
+			}
+			collectUnknown(childPath, child, paths)
+		}
+	case []interface{}:
+		for i, child := range v {
+			collectUnknown(fmt.Sprintf("%s[%d]", path, i), child, paths)
+		}
+	}
+}
+
+// annotateUnknown marks the nodes of resources with values only known after apply.
+// Collapsed resources are marked if any of their instances is.
+func (r *rover) annotateUnknown(nodes []Node) {
+	if r.Plan == nil {
+		return
+	}
+
+	// Addresses of the unknown resource instances, and of the resources collapsed instances belong to
+	unknown := map[string]bool{}
+	unknownResources := map[string]bool{}
+	for _, rc := range r.Plan.ResourceChanges {
+		if len(unknownAttributes(rc.Change)) == 0 {
+			continue
+		}
+
+		unknown[rc.Address] = true
+		if loc := instanceKeyPattern.FindStringIndex(rc.Address); loc != nil {
+			unknownResources[rc.Address[:loc[0]]] = true
+		}
+	}
+
+	for i, n := range nodes {
+		if !unknown[n.Data.ID] && !(n.Data.Instances > 0 && unknownResources[n.Data.ID]) {
+			continue
+		}
+
+		nodes[i].Data.Unknown = true
+		nodes[i].Classes = fmt.Sprintf("%s unknown", n.Classes)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// handleUpload replaces the assets with ones generated from the plan JSON posted in the request
+// body, up to --maxUploadBytes, and responds with the new summary. It's only served with
+// --allowUpload, and without CORS headers so other sites can't replace the visualization.
+func (ro *rover) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Plan must be uploaded with POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	planJson, err := io.ReadAll(http.MaxBytesReader(w, r.Body, ro.MaxUploadBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("Plan exceeds %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Error reading plan: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if !ro.refreshMu.TryLock() {
+		http.Error(w, "Refresh already in progress", http.StatusConflict)
+		return
+	}
+	defer ro.refreshMu.Unlock()
+
+	if err := ro.refreshAssets(planJson); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrPlanParse) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, fmt.Sprintf("Error refreshing assets: %s", err), status)
+		return
+	}
+
+	j, err := json.Marshal(ro.snapshot().summary())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error producing summary JSON: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, string(j))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHandleUpload(t *testing.T) {
+	planJson, err := os.ReadFile("testdata/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		maxUploadBytes int64
+		wantStatus     int
+		wantResources  int
+	}{
+		{
+			name:           "plan",
+			method:         http.MethodPost,
+			body:           string(planJson),
+			maxUploadBytes: 50 << 20,
+			wantStatus:     http.StatusOK,
+			wantResources:  4,
+		},
+		{
+			name:           "partial plan",
+			method:         http.MethodPost,
+			body:           `[{"address": "null_resource.d", "mode": "managed", "type": "null_resource", "name": "d", "change": {"actions": ["create"], "after": {}}}]`,
+			maxUploadBytes: 50 << 20,
+			wantStatus:     http.StatusOK,
+			wantResources:  1,
+		},
+		{
+			name:           "oversized plan",
+			method:         http.MethodPost,
+			body:           string(planJson),
+			maxUploadBytes: 100,
+			wantStatus:     http.StatusRequestEntityTooLarge,
+			wantResources:  4,
+		},
+		{
+			name:           "invalid plan",
+			method:         http.MethodPost,
+			body:           "{",
+			maxUploadBytes: 50 << 20,
+			wantStatus:     http.StatusBadRequest,
+			wantResources:  4,
+		},
+		{
+			name:           "GET",
+			method:         http.MethodGet,
+			maxUploadBytes: 50 << 20,
+			wantStatus:     http.StatusMethodNotAllowed,
+			wantResources:  4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testRover(t, "plan.json")
+			r.MaxUploadBytes = tt.maxUploadBytes
+			if err := r.generateAssets(); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			r.handleUpload(w, httptest.NewRequest(tt.method, "/api/upload", bytes.NewReader([]byte(tt.body))))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.wantStatus, strings.TrimSpace(w.Body.String()))
+			}
+			if got := len(r.snapshot().Plan.ResourceChanges); got != tt.wantResources {
+				t.Errorf("got %d resource changes, want %d", got, tt.wantResources)
+			}
+		})
+	}
+}
+
+func TestUploadRequiresAllowUpload(t *testing.T) {
+	planJson, err := os.ReadFile("testdata/replace.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, allowUpload := range []bool{false, true} {
+		r := testRover(t, "plan.json")
+		r.AllowUpload = allowUpload
+		r.MaxUploadBytes = 50 << 20
+		if err := r.generateAssets(); err != nil {
+			t.Fatal(err)
+		}
+
+		m := http.NewServeMux()
+		r.registerAssets(m, "", nil)
+		req := httptest.NewRequest(http.MethodPost, "/api/upload", bytes.NewReader(planJson))
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+
+		if allowUpload {
+			if w.Code != http.StatusOK {
+				t.Errorf("status %d: %s", w.Code, w.Body.String())
+			}
+			if origin := w.Header().Get("Access-Control-Allow-Origin"); origin != "" {
+				t.Errorf("Access-Control-Allow-Origin is %q, want none", origin)
+			}
+		}
+		if replaced := len(r.snapshot().ReplaceReasons) > 0; replaced != allowUpload {
+			t.Errorf("allowUpload=%t: plan replaced is %t", allowUpload, replaced)
+		}
+	}
+}
+
+func TestUploadClearsStaleAnnotations(t *testing.T) {
+	r := testRover(t, "replace.json")
+	r.ShowApplyResult = true
+	if err := r.generateAssets(); err != nil {
+		t.Fatal(err)
+	}
+	r.ApplyResults = map[string]string{"null_resource.a": "errored"}
+
+	planJson, err := os.ReadFile("testdata/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.refreshAssets(planJson); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := r.snapshot()
+	if len(snap.ApplyResults) != 0 {
+		t.Errorf("ApplyResults = %v after upload, want none", snap.ApplyResults)
+	}
+	if !reflect.DeepEqual(snap.ReplaceReasons, map[string]string{}) {
+		t.Errorf("ReplaceReasons = %v after upload, want none", snap.ReplaceReasons)
+	}
+	for _, n := range snap.Graph.Nodes {
+		if n.Data.ApplyResult != "" || n.Data.ReplaceReason != "" {
+			t.Errorf("node %s has apply result %q and replace reason %q from the previous plan", n.Data.ID, n.Data.ApplyResult, n.Data.ReplaceReason)
+		}
+	}
+}
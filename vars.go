@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+// tfVarsOrder returns whether each --tfVarsFile (true) and --tfVar (false) in args comes first,
+// in the order they were given. Terraform lets the last of them setting a variable win.
+// It returns nil if the order doesn't match the number of parsed values.
+func tfVarsOrder(args []string, tfVarsFiles []string, tfVars []string) []bool {
+	order := []bool{}
+	files, vars := 0, 0
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--tfVarsFile" || strings.HasPrefix(args[i], "--tfVarsFile="):
+			order = append(order, true)
+			files++
+		case args[i] == "--tfVar" || strings.HasPrefix(args[i], "--tfVar="):
+			order = append(order, false)
+			vars++
+		default:
+			continue
+		}
+
+		// Skip the value so a value looking like a flag isn't counted
+		if !strings.Contains(args[i], "=") {
+			i++
+		}
+	}
+
+	if files != len(tfVarsFiles) || vars != len(tfVars) {
+		return nil
+	}
+
+	return order
+}
+
+// varOptions returns the -var-file and -var options of the plan, applying --tfVarsFile and --tfVar
+// in command line order like Terraform. tfexec always passes -var-file options before -var ones,
+// so variables that a later --tfVarsFile sets again are left out instead.
+func (r *rover) varOptions(tmpDir string) ([]tfexec.PlanOption, error) {
+	order := r.TfVarsOrder
+	if order == nil {
+		// Without a known order, the files come first like tfexec passes them
+		for range r.TfVarsFiles {
+			order = append(order, true)
+		}
+		for range r.TfVars {
+			order = append(order, false)
+		}
+	}
+
+	// A --tfVarsFile and the variables it sets, or a --tfVar
+	type varArg struct {
+		file  string
+		names map[string]bool
+		tfVar string
+	}
+	args := []varArg{}
+
+	files, vars := 0, 0
+	for _, isFile := range order {
+		if !isFile {
+			tfVar := r.TfVars[vars]
+			vars++
+			if tfVar == "" {
+				continue
+			}
+
+			args = append(args, varArg{tfVar: tfVar})
+			continue
+		}
+
+		i := files
+		tfVarsFile := r.TfVarsFiles[files]
+		files++
+		if tfVarsFile == "" {
+			continue
+		}
+
+		if r.ExpandEnvVars {
+			var err error
+			tfVarsFile, err = r.expandVarsFile(tmpDir, i, tfVarsFile)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		names, err := r.varsFileNames(tfVarsFile)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, varArg{file: tfVarsFile, names: names})
+	}
+
+	var options []tfexec.PlanOption
+	for _, arg := range args {
+		if arg.file != "" {
+			options = append(options, tfexec.VarFile(arg.file))
+		}
+	}
+	for i, arg := range args {
+		if arg.file != "" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(arg.tfVar, "=")
+		name = strings.TrimSpace(name)
+
+		overridden := false
+		for _, later := range args[i+1:] {
+			overridden = overridden || later.names[name]
+		}
+		if overridden {
+			log.Printf("Leaving out --tfVar %s, a later --tfVarsFile sets it again", name)
+			continue
+		}
+
+		options = append(options, tfexec.Var(arg.tfVar))
+	}
+
+	return options, nil
+}
+
+// varsFileNames returns the names of the variables a tfvars file sets
+func (r *rover) varsFileNames(tfVarsFile string) (map[string]bool, error) {
+	// Terraform resolves tfvars files relative to the working directory
+	path := tfVarsFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.WorkingDir, path)
+	}
+
+	parser := hclparse.NewParser()
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(path, ".json") {
+		file, diags = parser.ParseJSONFile(path)
+	} else {
+		file, diags = parser.ParseHCLFile(path)
+	}
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("unable to read tfvars file (%s): %s", tfVarsFile, diags.Error())
+	}
+
+	attributes, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("unable to read tfvars file (%s): %s", tfVarsFile, diags.Error())
+	}
+
+	names := map[string]bool{}
+	for name := range attributes {
+		names[name] = true
+	}
+
+	return names, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+func TestTfVarsOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		tfVarsFiles []string
+		tfVars      []string
+		want        []bool
+	}{
+		{
+			name: "no variables",
+			args: []string{"rover", "--standalone"},
+			want: []bool{},
+		},
+		{
+			name:        "interleaved files and vars",
+			args:        []string{"rover", "--tfVar", "a=1", "--tfVarsFile", "x.tfvars", "--tfVar", "b=2", "--tfVarsFile", "y.tfvars"},
+			tfVarsFiles: []string{"x.tfvars", "y.tfvars"},
+			tfVars:      []string{"a=1", "b=2"},
+			want:        []bool{false, true, false, true},
+		},
+		{
+			name:        "values after equals signs",
+			args:        []string{"rover", "--tfVarsFile=x.tfvars", "--tfVar=a", "--tfVarsFile", "y.tfvars"},
+			tfVarsFiles: []string{"x.tfvars", "y.tfvars"},
+			tfVars:      []string{"a"},
+			want:        []bool{true, false, true},
+		},
+		{
+			name:        "values looking like flags",
+			args:        []string{"rover", "--tfVar", "--tfVarsFile", "--tfVarsFile", "x.tfvars"},
+			tfVarsFiles: []string{"x.tfvars"},
+			tfVars:      []string{"--tfVarsFile"},
+			want:        []bool{false, true},
+		},
+		{
+			name:        "counts not matching the parsed values",
+			args:        []string{"rover", "--tfVar", "a=1"},
+			tfVarsFiles: []string{"x.tfvars"},
+			tfVars:      []string{"a=1"},
+			want:        nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tfVarsOrder(tt.args, tt.tfVarsFiles, tt.tfVars)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tfVarsOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestVarOptions documents the precedence of --tfVarsFile and --tfVar: like with terraform plan,
+// the last one setting a variable wins. tfexec passes every -var-file before the -var options, so
+// a --tfVar followed by a --tfVarsFile setting the same variable is left out.
+func TestVarOptions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "x.tfvars"), []byte("a = \"x\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "y.tfvars.json"), []byte(`{"b": "y"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "my vars, prod.tfvars"), []byte("c = \"z\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		tfVarsFiles []string
+		tfVars      []string
+		order       []bool
+		want        []tfexec.PlanOption
+	}{
+		{
+			name:        "var after file setting it wins",
+			tfVarsFiles: []string{"x.tfvars"},
+			tfVars:      []string{"a=cli"},
+			order:       []bool{true, false},
+			want:        []tfexec.PlanOption{tfexec.VarFile("x.tfvars"), tfexec.Var("a=cli")},
+		},
+		{
+			name:        "file after var setting it wins",
+			tfVarsFiles: []string{"x.tfvars"},
+			tfVars:      []string{"a=cli"},
+			order:       []bool{false, true},
+			want:        []tfexec.PlanOption{tfexec.VarFile("x.tfvars")},
+		},
+		{
+			name:        "interleaved files and vars",
+			tfVarsFiles: []string{"x.tfvars", "y.tfvars.json"},
+			tfVars:      []string{"b=cli", "a=cli", "c=cli"},
+			order:       []bool{false, true, false, true, false},
+			want: []tfexec.PlanOption{
+				tfexec.VarFile("x.tfvars"), tfexec.VarFile("y.tfvars.json"),
+				tfexec.Var("a=cli"), tfexec.Var("c=cli"),
+			},
+		},
+		{
+			name:        "commas, spaces and equals signs are kept",
+			tfVarsFiles: []string{"my vars, prod.tfvars"},
+			tfVars:      []string{`zones=["a","b"]`, "greeting=hello, world", "query=a=b"},
+			order:       []bool{true, false, false, false},
+			want: []tfexec.PlanOption{
+				tfexec.VarFile("my vars, prod.tfvars"),
+				tfexec.Var(`zones=["a","b"]`),
+				tfexec.Var("greeting=hello, world"),
+				tfexec.Var("query=a=b"),
+			},
+		},
+		{
+			name:        "unknown order puts files first",
+			tfVarsFiles: []string{"x.tfvars"},
+			tfVars:      []string{"a=cli"},
+			order:       nil,
+			want:        []tfexec.PlanOption{tfexec.VarFile("x.tfvars"), tfexec.Var("a=cli")},
+		},
+		{
+			name:        "empty values are skipped",
+			tfVarsFiles: []string{""},
+			tfVars:      []string{""},
+			order:       []bool{true, false},
+			want:        nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rover{
+				WorkingDir:  dir,
+				TfVarsFiles: tt.tfVarsFiles,
+				TfVars:      tt.tfVars,
+				TfVarsOrder: tt.order,
+			}
+
+			got, err := r.varOptions(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("varOptions() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+// MIN_TF_VERSION is the oldest version producing plan JSON Rover can parse
+const MIN_TF_VERSION = "0.12.0"
+
+// Meta describes the running Rover instance and the binary it uses
+type Meta struct {
+	Version   string `json:"version"`
+	Engine    Engine `json:"engine"`
+	TfVersion string `json:"tf_version,omitempty"`
+}
+
+// checkVersion detects the version behind --tfPath and fails if it is too old
+func (r *rover) checkVersion(tf *tfexec.Terraform) error {
+	tfVersion, _, err := tf.Version(context.Background(), false)
+	if err != nil {
+		return fmt.Errorf("unable to detect %s version (%s): %s", r.Engine.Name(), r.TfPath, err)
+	}
+
+	r.TfVersion = tfVersion.String()
+	log.Printf("Using %s v%s", r.Engine.Name(), r.TfVersion)
+
+	if tfVersion.LessThan(version.Must(version.NewVersion(MIN_TF_VERSION))) {
+		return fmt.Errorf("%s v%s is not supported, please upgrade to v%s or later", r.Engine.Name(), r.TfVersion, MIN_TF_VERSION)
+	}
+
+	return nil
+}
+
+func (r *rover) meta() Meta {
+	return Meta{
+		Version:   VERSION,
+		Engine:    r.Engine,
+		TfVersion: r.TfVersion,
+	}
+}
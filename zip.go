@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
-	"log"
 	"os"
 	"strings"
 )
@@ -19,13 +18,18 @@ func (r *rover) generateZip(fe fs.FS, filename string) error {
 	}
 	defer newZipFile.Close()
 
-	zipWriter := zip.NewWriter(newZipFile)
+	return r.writeZip(fe, newZipFile)
+}
+
+// writeZip writes the standalone frontend and assets as a zip archive to w
+func (r *rover) writeZip(fe fs.FS, w io.Writer) error {
+	zipWriter := zip.NewWriter(w)
 	defer zipWriter.Close()
 
 	// Add frontend to zip file
 	feItems, err := fs.ReadDir(fe, ".")
 	if err != nil {
-		log.Fatalln(err)
+		return err
 	}
 
 	for _, feItem := range feItems {
@@ -48,7 +52,7 @@ func (r *rover) generateZip(fe fs.FS, filename string) error {
 		}
 	}
 
-	// Add plan, rso, map, graph to zip file
+	// Add plan, rso, map, graph, diagnostics and frontend config to zip file
 	if err = AddFileToZip(zipWriter, "plan", r.Plan); err != nil {
 		return err
 	}
@@ -61,6 +65,18 @@ func (r *rover) generateZip(fe fs.FS, filename string) error {
 	if err = AddFileToZip(zipWriter, "graph", r.Graph); err != nil {
 		return err
 	}
+	if err = AddFileToZip(zipWriter, "diagnostics", r.Diagnostics); err != nil {
+		return err
+	}
+
+	// The frontend config is named frontendConfig, since the frontend uses config for its graph styles
+	frontendConfig, err := r.exportFrontendConfig()
+	if err != nil {
+		return err
+	}
+	if err = AddFileToZip(zipWriter, "frontendConfig", frontendConfig); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -75,16 +91,10 @@ func AddEmbeddedToZip(fe fs.FS, zipWriter *zip.Writer, filename string) error {
 
 	// Rename standalone to index.html references from absolute to relative
 	if filename == "index.html" {
-		curContent, err := fs.ReadFile(fe, filename)
+		content, err := standaloneIndex(fe, ".")
 		if err != nil {
 			return err
 		}
-
-		contents := strings.Split(string(curContent), "</head>")
-		// Add js files, workaround since CORS error if you try to do getJSON
-		content := fmt.Sprintf("%s%s%s", contents[0], `<script type="text/javascript" language="javascript" src="./map.js"></script>
-		<script type="text/javascript" language="javascript" src="./rso.js"></script>
-		<script type="text/javascript" language="javascript" src="./graph.js"></script>`, contents[1])
 		content = strings.ReplaceAll(content, "=\"/", "=\"./")
 
 		tempFileName, tempFile, err := createTempFile("temp-index.html", []byte(content))
@@ -140,15 +150,12 @@ func AddFileToZip(zipWriter *zip.Writer, fileType string, j interface{}) error {
 		return err
 	}
 
-	b, err := json.Marshal(j)
+	content, err := standaloneJS(fileType, j)
 	if err != nil {
-		return fmt.Errorf("error producing JSON: %s", err)
+		return err
 	}
 
-	// add syntax to make json file a js object
-	content := fmt.Sprintf("const %s = %s", fileType, string(b))
-
-	tempFileName, tempFile, err := createTempFile(filename, []byte(content))
+	tempFileName, tempFile, err := createTempFile(filename, content)
 	if err != nil {
 		return err
 	}
@@ -165,15 +172,47 @@ func AddFileToZip(zipWriter *zip.Writer, fileType string, j interface{}) error {
 	return err
 }
 
+// standaloneIndex returns index.html loading the assets as js files from dir instead of the API
+func standaloneIndex(fe fs.FS, dir string) (string, error) {
+	curContent, err := fs.ReadFile(fe, "index.html")
+	if err != nil {
+		return "", err
+	}
+
+	contents := strings.Split(string(curContent), "</head>")
+	// Add js files, workaround since CORS error if you try to do getJSON
+	scripts := ""
+	for _, fileType := range []string{"map", "rso", "graph", "diagnostics", "frontendConfig"} {
+		scripts += fmt.Sprintf(`<script type="text/javascript" language="javascript" src="%s/%s.js"></script>`, dir, fileType)
+	}
+
+	return fmt.Sprintf("%s%s</head>%s", contents[0], scripts, contents[1]), nil
+}
+
+// standaloneJS returns an asset as a js file defining it as a global constant
+func standaloneJS(fileType string, j interface{}) ([]byte, error) {
+	b, err := json.Marshal(j)
+	if err != nil {
+		return nil, fmt.Errorf("error producing JSON: %s", err)
+	}
+
+	// add syntax to make json file a js object
+	return []byte(fmt.Sprintf("const %s = %s", fileType, string(b))), nil
+}
+
+// createTempFile writes b to a new temporary file named after filename. The caller closes and
+// removes the file, unless an error is returned.
 func createTempFile(filename string, b []byte) (string, *os.File, error) {
 	tempFile, err := os.CreateTemp("", filename)
 	if err != nil {
-		log.Fatal(err)
+		return "", nil, fmt.Errorf("unable to create temporary file for %s: %w", filename, err)
 	}
 
 	_, err = tempFile.Write(b)
 	if err != nil {
-		return "", tempFile, err
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+		return "", nil, fmt.Errorf("unable to write temporary file for %s: %w", filename, err)
 	}
 
 	return tempFile.Name(), tempFile, nil
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWriteZip(t *testing.T) {
+	r := &rover{}
+	fe := fstest.MapFS{
+		"index.html": &fstest.MapFile{Data: []byte(`<html><head><script src="/js/app.js"></script></head><body></body></html>`)},
+		"js/app.js":  &fstest.MapFile{Data: []byte(`r.p+"js/chunk.js"`)},
+	}
+
+	var buf bytes.Buffer
+	if err := r.writeZip(fe, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(content)
+	}
+
+	for _, name := range []string{"index.html", "js/app.js", "plan.js", "rso.js", "map.js", "graph.js"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("zip doesn't contain %s", name)
+		}
+	}
+	for _, want := range []string{`src="./js/app.js"`, `src="./map.js"`, `src="./rso.js"`, `src="./graph.js"`} {
+		if !strings.Contains(files["index.html"], want) {
+			t.Errorf("index.html doesn't contain %s: %s", want, files["index.html"])
+		}
+	}
+	if files["js/app.js"] != `"./js/chunk.js"` {
+		t.Errorf("js/app.js = %s, want chunks loaded relative to index.html", files["js/app.js"])
+	}
+	if !strings.HasPrefix(files["graph.js"], "const graph = ") {
+		t.Errorf("graph.js = %s, want the graph defined as a constant", files["graph.js"])
+	}
+}
+
+func TestDownloadTempFileError(t *testing.T) {
+	r := testRover(t, "plan.json")
+	if err := r.generateAssets(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Temporary files can't be created in a directory that doesn't exist
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+
+	fe := fstest.MapFS{
+		"index.html": &fstest.MapFile{Data: []byte("<html><head></head><body></body></html>")},
+	}
+	m := http.NewServeMux()
+	r.registerAssets(m, "", fe)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/download", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if !strings.Contains(w.Body.String(), "unable to create temporary file") {
+		t.Errorf("body %q doesn't contain the temporary file error", w.Body.String())
+	}
+}